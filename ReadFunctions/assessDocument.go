@@ -0,0 +1,163 @@
+package ReadFunctions
+
+import (
+	"math"
+	"sort"
+)
+
+// clinicalTypes are the detection types for clinical vocabulary: diagnosis,
+// procedure, drug and laboratory codes.
+var clinicalTypes = map[string]bool{"icd10": true, "cpt": true, "ndc": true, "loinc": true}
+
+// typeEvidence is the document type each kind of detection points to and how much
+// its confidence counts. Provider numbers appear on medical paperwork but also in
+// directories and claims, so they count for half. ITINs, reported as "ssn" with
+// Subtype "itin", count as financial too.
+var typeEvidence = map[string]struct {
+	documentType string
+	weight       float64
+}{
+	"icd10": {"medical", 1}, "cpt": {"medical", 1}, "ndc": {"medical", 1}, "loinc": {"medical", 1},
+	"mrn": {"medical", 1}, "mbi": {"medical", 1}, "health_plan": {"medical", 1},
+	"npi": {"medical", 0.5}, "dea": {"medical", 0.5},
+	"iban": {"financial", 1}, "aba_routing": {"financial", 1}, "swift_bic": {"financial", 1},
+	"bank_account": {"financial", 1}, "ein": {"financial", 1}, "credit_card": {"financial", 1},
+}
+
+// directIdentifierTypes identify an individual on their own. NPI and DEA numbers
+// identify providers rather than patients, so they aren't included.
+var directIdentifierTypes = map[string]bool{
+	"ssn": true, "email": true, "phone": true, "name": true, "address": true, "dob": true,
+	"credit_card": true, "mrn": true, "mbi": true, "health_plan": true, "national_id": true,
+}
+
+// contextualPHITypes are identifiers HIPAA lists that say nothing about health on
+// their own: network addresses, device and vehicle numbers and personal URLs. They
+// are filed as PII, and moved to PHI in files classified as medical, where they
+// identify a patient's record.
+var contextualPHITypes = map[string]bool{
+	"ip_address": true, "mac_address": true, "url": true, "imei": true, "vin": true,
+}
+
+// documentEvidence is the score a document type needs for a file to be classified
+// as that type: two codes found without keywords, one with, or two different strong
+// document keywords.
+const documentEvidence = 0.8
+
+// maxDocumentSignals is how many signals are reported with a DocumentType.
+const maxDocumentSignals = 5
+
+// DocumentSignal is one kind of evidence behind a file's DocumentType: a detection
+// type such as "icd10", or a document keyword such as "plaintiff".
+type DocumentSignal struct {
+	Signal string  `json:"signal"`
+	Kind   string  `json:"kind"` // "detection" or "keyword"
+	Count  int     `json:"count"`
+	Score  float64 `json:"score"` // what it added to the document type's score
+}
+
+// assess classifies the file and then scores it with scoreRisk.
+//
+// Each document type scores the summed confidence of the detections that point to
+// it, weighted by typeEvidence, plus the weights of the different keywords found for
+// it; a keyword counts once however often it appears, so one word repeated down a
+// file can't classify it on its own. A file with enough medical evidence is medical
+// whatever else it holds, since health information rules then apply to all of it;
+// otherwise the highest scoring type with enough evidence wins.
+// DocumentTypeConfidence is s/(s+0.5) for the winning score s, how strong the
+// evidence is, times s over the sum of all types' scores, how clearly it beats the
+// others.
+func (f *FileAttributes) assess() {
+	clinical := 0.0
+	signals := map[[3]string]*DocumentSignal{} // by document type, kind and signal
+	scores := map[string]float64{}
+	add := func(documentType, kind, name string, score float64) {
+		key := [3]string{documentType, kind, name}
+		if signals[key] == nil {
+			signals[key] = &DocumentSignal{Signal: name, Kind: kind}
+		}
+		s := signals[key]
+		s.Count++
+		if kind == "keyword" && s.Count > 1 {
+			return
+		}
+		s.Score += score
+		scores[documentType] += score
+	}
+
+	for _, detections := range [][]PIIDetection{f.PIIDetections, f.PHIDetections} {
+		for _, d := range detections {
+			if clinicalTypes[d.Type] {
+				clinical += d.Confidence
+			}
+			if e, ok := typeEvidence[d.Type]; ok {
+				add(e.documentType, "detection", d.Type, e.weight*d.Confidence)
+			} else if d.Subtype == "itin" {
+				add("financial", "detection", "itin", d.Confidence)
+			}
+		}
+	}
+	for _, k := range f.keywords {
+		add(k.Subtype, "keyword", k.Value, k.Confidence)
+	}
+
+	f.DocumentType = ""
+	total := 0.0
+	for documentType, score := range scores {
+		total += score
+		if score >= documentEvidence && (f.DocumentType == "" || score > scores[f.DocumentType] ||
+			score == scores[f.DocumentType] && documentType < f.DocumentType) {
+			f.DocumentType = documentType
+		}
+	}
+	if scores["medical"] >= documentEvidence {
+		f.DocumentType = "medical"
+	}
+
+	if f.DocumentType != "" {
+		s := scores[f.DocumentType]
+		f.DocumentTypeConfidence = math.Round(s/(s+0.5)*s/total*100) / 100
+		f.DocumentSignals = topSignals(signals, f.DocumentType)
+	}
+	if f.DocumentType == "medical" {
+		f.moveContextualPHI()
+	}
+
+	f.scoreRisk(clinical)
+}
+
+// topSignals returns the strongest maxDocumentSignals signals for documentType.
+func topSignals(signals map[[3]string]*DocumentSignal, documentType string) []DocumentSignal {
+	var top []DocumentSignal
+	for key, s := range signals {
+		if key[0] == documentType {
+			top = append(top, DocumentSignal{Signal: s.Signal, Kind: s.Kind, Count: s.Count, Score: math.Round(s.Score*100) / 100})
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Score != top[j].Score {
+			return top[i].Score > top[j].Score
+		}
+		return top[i].Signal < top[j].Signal
+	})
+	if len(top) > maxDocumentSignals {
+		top = top[:maxDocumentSignals]
+	}
+	return top
+}
+
+// moveContextualPHI moves contextualPHITypes detections from PIIDetections to
+// PHIDetections and updates the totals.
+func (f *FileAttributes) moveContextualPHI() {
+	pii := f.PIIDetections[:0]
+	for _, d := range f.PIIDetections {
+		if contextualPHITypes[d.Type] {
+			f.PHIDetections = append(f.PHIDetections, d)
+		} else {
+			pii = append(pii, d)
+		}
+	}
+	f.PIIDetections = pii
+	f.TotalPIICount = len(f.PIIDetections)
+	f.TotalPHICount = len(f.PHIDetections)
+}
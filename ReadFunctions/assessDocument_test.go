@@ -0,0 +1,105 @@
+package ReadFunctions
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAssess(t *testing.T) {
+	tests := []struct {
+		name         string
+		detections   []PIIDetection
+		documentType string
+		risk         float64
+	}{
+		{"nothing found", nil, "", 0},
+		{"identifier only", []PIIDetection{{Type: "ssn", Confidence: 0.8}}, "", 0.807},
+		{"codes only", []PIIDetection{{Type: "icd10", Confidence: 0.9}, {Type: "cpt", Confidence: 0.85}}, "medical", 0.194},
+		{"one weak code", []PIIDetection{{Type: "icd10", Confidence: 0.45}}, "", 0.094},
+		{"identifier and codes", []PIIDetection{{Type: "mrn", Confidence: 1}, {Type: "icd10", Confidence: 0.9}},
+			"medical", 0.946},
+		{"bank details", []PIIDetection{{Type: "iban", Confidence: 0.95}, {Type: "name", Confidence: 0.5}},
+			"financial", 0.816},
+		{"ITIN and EIN", []PIIDetection{{Type: "ssn", Subtype: "itin", Confidence: 0.4}, {Type: "ein", Confidence: 0.5}},
+			"financial", 0.416},
+		{"codes win over bank details", []PIIDetection{{Type: "iban", Confidence: 0.95}, {Type: "icd10", Confidence: 0.9}},
+			"medical", 0.816},
+		{"provider numbers aren't direct identifiers", []PIIDetection{{Type: "npi", Confidence: 0.9}}, "", 0.094},
+	}
+
+	for _, tt := range tests {
+		var f FileAttributes
+		f.addDetections(tt.detections)
+		f.assess()
+		if f.DocumentType != tt.documentType || math.Abs(f.RiskScore-tt.risk) > 5e-4 {
+			t.Errorf("%s: DocumentType %q RiskScore %.3f; want %q %.3f",
+				tt.name, f.DocumentType, f.RiskScore, tt.documentType, tt.risk)
+		}
+	}
+}
+
+func TestAssessMovesContextualPHI(t *testing.T) {
+	detections := []PIIDetection{{Type: "ip_address", Confidence: 0.8}, {Type: "email", Confidence: 0.9}}
+
+	var plain FileAttributes
+	plain.addDetections(detections)
+	plain.assess()
+	if plain.TotalPIICount != 2 || plain.TotalPHICount != 0 {
+		t.Errorf("outside a medical file: %d PII %d PHI; want 2 0", plain.TotalPIICount, plain.TotalPHICount)
+	}
+
+	var medical FileAttributes
+	medical.addDetections(append(detections, PIIDetection{Type: "icd10", Confidence: 0.9}))
+	medical.assess()
+	if medical.TotalPIICount != 1 || medical.TotalPHICount != 2 || medical.PHIDetections[1].Type != "ip_address" {
+		t.Errorf("in a medical file: %d PII %d PHI %v; want email as PII and ip_address as PHI",
+			medical.TotalPIICount, medical.TotalPHICount, medical.PHIDetections)
+	}
+}
+
+func TestAssessDocumentType(t *testing.T) {
+	keyword := func(documentType, word string, weight float64) PIIDetection {
+		return PIIDetection{Type: "keyword", Subtype: documentType, Value: word, Confidence: weight}
+	}
+
+	tests := []struct {
+		name         string
+		detections   []PIIDetection
+		documentType string
+		confidence   float64
+		topSignal    string
+	}{
+		{"court filing", []PIIDetection{keyword("legal", "plaintiff", 0.4), keyword("legal", "defendant", 0.4),
+			keyword("legal", "case number", 0.5), {Type: "name", Confidence: 0.8}}, "legal", 0.72, "case number"},
+		{"personnel file", []PIIDetection{keyword("hr", "payroll", 0.4), keyword("hr", "hire date", 0.4),
+			keyword("financial", "bank", 0.2)}, "hr", 0.49, "hire date"},
+		{"keywords and codes", []PIIDetection{keyword("medical", "patient", 0.4), {Type: "icd10", Confidence: 0.9}},
+			"medical", 0.72, "icd10"},
+		{"one keyword isn't enough", []PIIDetection{keyword("legal", "plaintiff", 0.4)}, "", 0, ""},
+		{"one keyword repeated isn't enough", []PIIDetection{keyword("medical", "patient", 0.4),
+			keyword("medical", "patient", 0.4), {Type: "ip_address", Confidence: 0.8}}, "", 0, ""},
+		{"repeats stop counting", []PIIDetection{keyword("hr", "employee", 0.2), keyword("hr", "employee", 0.2),
+			keyword("hr", "employee", 0.2), keyword("hr", "employee", 0.2), keyword("hr", "employee", 0.2)}, "", 0, ""},
+	}
+
+	for _, tt := range tests {
+		var f FileAttributes
+		f.addDetections(tt.detections)
+		f.assess()
+		if f.DocumentType != tt.documentType || f.DocumentTypeConfidence != tt.confidence {
+			t.Errorf("%s: DocumentType %q confidence %.2f; want %q %.2f",
+				tt.name, f.DocumentType, f.DocumentTypeConfidence, tt.documentType, tt.confidence)
+		}
+		if top := ""; tt.topSignal != "" {
+			if len(f.DocumentSignals) > 0 {
+				top = f.DocumentSignals[0].Signal
+			}
+			if top != tt.topSignal {
+				t.Errorf("%s: top signal %q (%v); want %q", tt.name, top, f.DocumentSignals, tt.topSignal)
+			}
+		}
+		if f.TotalPIICount+f.TotalPHICount != len(tt.detections)-len(f.keywords) {
+			t.Errorf("%s: keywords reported as findings", tt.name)
+		}
+	}
+}
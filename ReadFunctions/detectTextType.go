@@ -0,0 +1,351 @@
+package ReadFunctions
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// textSniffLength is how much of a file DetectFileType reads to identify it.
+const textSniffLength = 8192
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// extensionHints maps file extensions to the text type they usually hold. Content
+// sniffing decides when there is no hint, and JSON hints are only trusted when the
+// content parses, since the JSON reader can't recover from anything else.
+var extensionHints = map[string]string{
+	".json": "json", ".jsonl": "json", ".ndjson": "json", ".geojson": "json",
+	".csv": "csv", ".tsv": "csv", ".psv": "csv", ".tab": "csv",
+	".sql": "sql", ".dump": "sql",
+	".txt": "txt", ".log": "txt", ".md": "txt", ".text": "txt",
+}
+
+// sniffTextType decides whether sample, the start of a file, is text and if so
+// which of the text readers should handle it. truncated reports that the file is
+// longer than the sample, so an incomplete final character or JSON value is expected.
+func sniffTextType(filePath string, sample []byte, truncated bool) (fileType, encoding string, ok bool) {
+	text, encoding, ok := decodeTextSample(sample, truncated)
+	if !ok {
+		return "", "", false
+	}
+
+	hint := extensionHints[strings.ToLower(filepath.Ext(filePath))]
+	switch hint {
+	case "csv", "sql", "txt":
+		return hint, encoding, true
+	}
+
+	switch {
+	case looksLikeJSON(text, truncated):
+		return "json", encoding, true
+	case looksLikeSQL(text):
+		return "sql", encoding, true
+	case csvDelimiter(text) != 0:
+		return "csv", encoding, true
+	default:
+		return "txt", encoding, true
+	}
+}
+
+// decodeTextSample checks for a byte order mark or UTF-16 without one, and returns
+// the sample as UTF-8 if it is valid text with few control characters.
+func decodeTextSample(sample []byte, truncated bool) (string, string, bool) {
+	encoding := "utf-8"
+	switch {
+	case bytes.HasPrefix(sample, bomUTF8):
+		sample = sample[len(bomUTF8):]
+	case bytes.HasPrefix(sample, bomUTF16LE):
+		encoding = "utf-16le"
+	case bytes.HasPrefix(sample, bomUTF16BE):
+		encoding = "utf-16be"
+	default:
+		encoding = guessUTF16(sample)
+	}
+
+	if encoding != "utf-8" {
+		decoded, err := io.ReadAll(textReader(bytes.NewReader(sample), encoding))
+		if err != nil {
+			return "", "", false
+		}
+		sample = decoded
+	} else if truncated {
+		// The sample may end part way through a multibyte character.
+		for i := 0; i < utf8.UTFMax-1 && len(sample) > 0 && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+
+	if !utf8.Valid(sample) {
+		return "", "", false
+	}
+
+	control := 0
+	for _, r := range string(sample) {
+		switch {
+		case r == 0:
+			return "", "", false
+		case r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f':
+			control++
+		}
+	}
+	if len(sample) > 0 && control*100/len(sample) > 1 {
+		return "", "", false
+	}
+	return string(sample), encoding, true
+}
+
+// guessUTF16 recognises UTF-16 without a byte order mark from the zero bytes that
+// mostly-ASCII text leaves in every other position.
+func guessUTF16(sample []byte) string {
+	if len(sample) < 4 {
+		return "utf-8"
+	}
+	evenZeros, oddZeros := 0, 0
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	half := len(sample) / 2
+	switch {
+	case oddZeros > half*3/4 && evenZeros < half/10:
+		return "utf-16le"
+	case evenZeros > half*3/4 && oddZeros < half/10:
+		return "utf-16be"
+	}
+	return "utf-8"
+}
+
+// looksLikeJSON reports whether text is a JSON document, or newline-delimited JSON,
+// at least as far as the sample goes.
+func looksLikeJSON(text string, truncated bool) bool {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || trimmed[0] != '{' && trimmed[0] != '[' {
+		return false
+	}
+
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	values := 0
+	for {
+		var v json.RawMessage
+		err := dec.Decode(&v)
+		switch {
+		case err == nil:
+			values++
+			continue
+		case errors.Is(err, io.EOF):
+			return values > 0
+		case truncated && errors.Is(err, io.ErrUnexpectedEOF):
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+var (
+	sqlStatement  = regexp.MustCompile(`(?im)^\s*(CREATE\s+(TABLE|INDEX|SCHEMA|DATABASE|SEQUENCE|VIEW)|INSERT\s+INTO|COPY\s+\S+.*FROM\s+stdin|ALTER\s+TABLE|DROP\s+TABLE|LOCK\s+TABLES|UPDATE\s+\S+\s+SET|SELECT\s+.+\s+FROM)\b`)
+	sqlDumpHeader = regexp.MustCompile(`(?i)--\s*(MySQL dump|PostgreSQL database dump|Dumping data for table)`)
+)
+
+// looksLikeSQL reports whether text reads like a SQL script or database dump.
+func looksLikeSQL(text string) bool {
+	if sqlDumpHeader.MatchString(text) {
+		return true
+	}
+	statements := len(sqlStatement.FindAllStringIndex(text, 3))
+	return statements >= 2 || statements == 1 && strings.Contains(text, ";")
+}
+
+// csvDelimiter infers the delimiter of delimited text: the candidate that appears,
+// outside quotes, the same non-zero number of times on nearly every line. It returns
+// 0 when the text doesn't look delimited.
+func csvDelimiter(text string) rune {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(lines) > 1 {
+		lines = lines[:len(lines)-1] // the last line may be cut off
+	}
+	if len(lines) > 50 {
+		lines = lines[:50]
+	}
+
+	var best rune
+	bestCount := 0
+	for _, delim := range []rune{',', '\t', ';', '|'} {
+		counts := map[int]int{}
+		nonEmpty := 0
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			nonEmpty++
+			counts[countUnquoted(line, delim)]++
+		}
+		if nonEmpty < 2 {
+			continue
+		}
+
+		mode, modeLines := 0, 0
+		for count, n := range counts {
+			if n > modeLines || n == modeLines && count > mode {
+				mode, modeLines = count, n
+			}
+		}
+		if mode == 0 || modeLines*10 < nonEmpty*8 {
+			continue
+		}
+		if mode > bestCount {
+			best, bestCount = delim, mode
+		}
+	}
+	return best
+}
+
+// countUnquoted counts delim in line, ignoring any inside double-quoted fields.
+func countUnquoted(line string, delim rune) int {
+	count := 0
+	quoted := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == delim && !quoted:
+			count++
+		}
+	}
+	return count
+}
+
+// textReader returns r converted to UTF-8 for the given encoding, dropping any byte
+// order mark. An empty encoding leaves r untouched.
+func textReader(r io.Reader, encoding string) io.Reader {
+	switch encoding {
+	case "utf-16le":
+		return &utf16Reader{r: r, order: binary.LittleEndian}
+	case "utf-16be":
+		return &utf16Reader{r: r, order: binary.BigEndian}
+	case "utf-8":
+		return &bomReader{r: r}
+	default:
+		return r
+	}
+}
+
+// bomReader strips a leading UTF-8 byte order mark.
+type bomReader struct {
+	r       io.Reader
+	checked bool
+}
+
+func (b *bomReader) Read(p []byte) (int, error) {
+	if b.checked {
+		return b.r.Read(p)
+	}
+	b.checked = true
+
+	head := make([]byte, len(bomUTF8))
+	n, err := io.ReadFull(b.r, head)
+	head = head[:n]
+	if bytes.Equal(head, bomUTF8) {
+		head = nil
+	}
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	b.r = io.MultiReader(bytes.NewReader(head), errReader{err: err, r: b.r})
+	return b.r.Read(p)
+}
+
+// errReader returns err once the head of a stream has been consumed, or reads on
+// from r when there was no error.
+type errReader struct {
+	err error
+	r   io.Reader
+}
+
+func (e errReader) Read(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	return e.r.Read(p)
+}
+
+// utf16Reader decodes a UTF-16 stream to UTF-8.
+type utf16Reader struct {
+	r       io.Reader
+	order   binary.ByteOrder
+	in, out []byte
+	err     error
+	started bool
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	for len(u.out) == 0 {
+		if u.err != nil {
+			return 0, u.err
+		}
+		buf := make([]byte, 4096)
+		n, err := u.r.Read(buf)
+		u.in = append(u.in, buf[:n]...)
+		u.err = err
+		u.decode(err != nil)
+	}
+	n := copy(p, u.out)
+	u.out = u.out[n:]
+	return n, nil
+}
+
+// decode converts whole code units from u.in, holding back a trailing odd byte or
+// unpaired high surrogate until more input arrives.
+func (u *utf16Reader) decode(final bool) {
+	if !u.started && len(u.in) >= 2 {
+		u.started = true
+		if u.order.Uint16(u.in) == 0xFEFF {
+			u.in = u.in[2:]
+		}
+	}
+
+	for len(u.in) >= 2 {
+		c := rune(u.order.Uint16(u.in))
+		if c >= 0xD800 && c < 0xDC00 { // high surrogate, needs the next unit
+			if len(u.in) < 4 {
+				if !final {
+					break
+				}
+				u.out = utf8.AppendRune(u.out, utf8.RuneError)
+				u.in = u.in[2:]
+				continue
+			}
+			if r := utf16.DecodeRune(c, rune(u.order.Uint16(u.in[2:]))); r != utf8.RuneError {
+				u.out = utf8.AppendRune(u.out, r)
+				u.in = u.in[4:]
+				continue
+			}
+		}
+		if utf16.IsSurrogate(c) {
+			c = utf8.RuneError
+		}
+		u.out = utf8.AppendRune(u.out, c)
+		u.in = u.in[2:]
+	}
+	if final {
+		u.in = nil
+	}
+}
@@ -0,0 +1,111 @@
+package ReadFunctions
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func utf16LE(s string, bom bool) []byte {
+	var b []byte
+	if bom {
+		b = append(b, bomUTF16LE...)
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func TestDetectFileTypeText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  []byte
+		fileType string
+		encoding string
+		comment  string
+	}{
+		{"notes", []byte("Call Mary at 555-123-4567\nSSN 123-45-6789\n"), "txt", "utf-8", "Plain text"},
+		{"notes.txt", []byte("a,b,c\n1,2,3\n"), "txt", "utf-8", "Extension hint wins over CSV shape"},
+		{"export", []byte("name,ssn,dob\nJane,123-45-6789,01/02/1960\nJohn,987-65-4321,03/04/1970\n"), "csv", "utf-8", "Comma delimited"},
+		{"export", []byte("name\tssn\nJane\t123-45-6789\nJohn\t987-65-4321\n"), "csv", "utf-8", "Tab delimited"},
+		{"export", []byte("name;note\n\"Doe; Jane\";x\n\"Roe; John\";y\n"), "csv", "utf-8", "Quoted delimiters ignored"},
+		{"data.tsv", []byte("single column\nvalue\n"), "csv", "utf-8", "Extension hint for single column"},
+		{"api", []byte(`{"patients": [{"ssn": "123-45-6789"}]}`), "json", "utf-8", "JSON object"},
+		{"api", []byte("{\"a\": 1}\n{\"a\": 2}\n"), "json", "utf-8", "NDJSON"},
+		{"bad.json", []byte("{not json at all"), "txt", "utf-8", "Unparseable JSON hint falls back"},
+		{"dump", []byte("-- MySQL dump 10.13\nCREATE TABLE users (id int);\n"), "sql", "utf-8", "MySQL dump header"},
+		{"script", []byte("CREATE TABLE t (a int);\nINSERT INTO t VALUES (1);\n"), "sql", "utf-8", "SQL statements"},
+		{"bom", append(append([]byte{}, bomUTF8...), "hello world\n"...), "txt", "utf-8", "UTF-8 BOM"},
+		{"wide", utf16LE("name,ssn\nJane,123-45-6789\nJohn,987-65-4321\n", true), "csv", "utf-16le", "UTF-16LE with BOM"},
+		{"wide", utf16LE("Patient Mary Johnson DOB 01/02/1960\n", false), "txt", "utf-16le", "UTF-16LE without BOM"},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range testCases {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, tt.content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		fileAttr, err := DetectFileType(path)
+		if err != nil {
+			t.Errorf("DetectFileType(%s) returned error: %v (%s)", tt.name, err, tt.comment)
+			continue
+		}
+		if fileAttr.FileType != tt.fileType || fileAttr.Encoding != tt.encoding {
+			t.Errorf("DetectFileType(%s) = %s/%s; want %s/%s (%s)",
+				tt.name, fileAttr.FileType, fileAttr.Encoding, tt.fileType, tt.encoding, tt.comment)
+		}
+	}
+}
+
+func TestDetectFileTypeBinary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blob.bin")
+	if err := os.WriteFile(path, []byte{0x00, 0x01, 0x02, 0x89, 0xff, 0x10, 0x00, 0x07}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DetectFileType(path); err == nil {
+		t.Error("DetectFileType accepted binary content")
+	}
+}
+
+func TestTextReader(t *testing.T) {
+	want := "SSN 123-45-6789 😀 naïve"
+
+	testCases := []struct {
+		encoding string
+		input    []byte
+	}{
+		{"utf-8", append(append([]byte{}, bomUTF8...), want...)},
+		{"utf-16le", utf16LE(want, true)},
+	}
+	for _, tt := range testCases {
+		// Read a byte at a time to exercise code units split across reads.
+		got, err := io.ReadAll(textReader(io.LimitReader(oneByteReader{bytes.NewReader(tt.input)}, 1<<20), tt.encoding))
+		if err != nil {
+			t.Errorf("%s: read error: %v", tt.encoding, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s: got %q; want %q", tt.encoding, got, want)
+		}
+	}
+
+	if got, _ := io.ReadAll(textReader(strings.NewReader("ab"), "utf-8")); string(got) != "ab" {
+		t.Errorf("short utf-8 input = %q; want %q", got, "ab")
+	}
+}
+
+type oneByteReader struct{ r io.Reader }
+
+func (o oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
@@ -0,0 +1,538 @@
+package ReadFunctions
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// PDF object model, as produced by pdfLexer.readObject:
+//
+//	null        nil
+//	boolean     bool
+//	number      float64
+//	name        pdfName
+//	string      []byte
+//	array       []any
+//	dictionary  pdfDict
+//	reference   pdfRef
+//	stream      *pdfStream
+//	operator    pdfKeyword (content streams only)
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfDict    map[pdfName]any
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict pdfDict
+		raw  []byte
+	}
+)
+
+// pdfMaxDecodedStream caps the decompressed size of a single stream.
+const pdfMaxDecodedStream = 256 * 1024 * 1024
+
+var errPDFSyntax = errors.New("malformed PDF")
+
+// pdfLexer reads PDF objects from a byte slice. The same lexer handles the file
+// body, object streams, content streams and CMaps.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skipSpace skips whitespace and comments.
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// readObject returns the next object, or io.EOF at the end of the data.
+func (l *pdfLexer) readObject() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		return l.readName(), nil
+	case c == '(':
+		return l.readLiteralString(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.readDict()
+	case c == '<':
+		return l.readHexString(), nil
+	case c == '[':
+		l.pos++
+		return l.readArray()
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return pdfKeyword(string(c)), nil
+	case c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9':
+		return l.readNumberOrRef(), nil
+	default:
+		word := l.readWord()
+		switch word {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return pdfKeyword(word), nil
+	}
+}
+
+func (l *pdfLexer) readWord() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start { // a stray delimiter, consume it so the caller makes progress
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) readName() pdfName {
+	l.pos++ // '/'
+	var name []byte
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		c := l.data[l.pos]
+		if c == '#' && l.pos+2 < len(l.data) {
+			if b, err := hex.DecodeString(string(l.data[l.pos+1 : l.pos+3])); err == nil {
+				name = append(name, b[0])
+				l.pos += 3
+				continue
+			}
+		}
+		name = append(name, c)
+		l.pos++
+	}
+	return pdfName(name)
+}
+
+func (l *pdfLexer) readLiteralString() []byte {
+	l.pos++ // '('
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					n := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (l *pdfLexer) readHexString() []byte {
+	l.pos++ // '<'
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		c := l.data[l.pos]
+		if c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F' {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // '>'
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out, _ := hex.DecodeString(string(digits))
+	return out
+}
+
+func (l *pdfLexer) readArray() ([]any, error) {
+	var arr []any
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			return arr, err
+		}
+		if kw, ok := obj.(pdfKeyword); ok && kw == "]" {
+			return arr, nil
+		}
+		arr = append(arr, obj)
+	}
+}
+
+func (l *pdfLexer) readDict() (any, error) {
+	dict := pdfDict{}
+	for {
+		l.skipSpace()
+		if l.pos+1 < len(l.data) && l.data[l.pos] == '>' && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			break
+		}
+		key, err := l.readObject()
+		if err != nil {
+			return dict, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			continue // tolerate junk between entries
+		}
+		value, err := l.readObject()
+		if err != nil {
+			return dict, err
+		}
+		dict[name] = value
+	}
+
+	// A dictionary followed by the stream keyword is a stream object.
+	save := l.pos
+	l.skipSpace()
+	if !bytes.HasPrefix(l.data[l.pos:], []byte("stream")) {
+		l.pos = save
+		return dict, nil
+	}
+	l.pos += len("stream")
+	if l.pos < len(l.data) && l.data[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+		l.pos++
+	}
+
+	start := l.pos
+	end := -1
+	// Compare as float64, so a huge /Length can't overflow int.
+	if n, ok := dict["Length"].(float64); ok && n >= 0 && n <= float64(len(l.data)-start) {
+		after := l.data[start+int(n):]
+		trimmed := bytes.TrimLeft(after, " \t\r\n")
+		if bytes.HasPrefix(trimmed, []byte("endstream")) {
+			end = start + int(n)
+		}
+	}
+	if end < 0 { // indirect or wrong /Length, fall back to searching for endstream
+		idx := bytes.Index(l.data[start:], []byte("endstream"))
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: unterminated stream", errPDFSyntax)
+		}
+		end = start + idx
+		for end > start && (l.data[end-1] == '\n' || l.data[end-1] == '\r') {
+			end--
+		}
+	}
+
+	l.pos = end
+	l.skipSpace()
+	l.pos += len("endstream")
+	return &pdfStream{dict: dict, raw: l.data[start:end]}, nil
+}
+
+// readNumberOrRef reads a number, or an indirect reference "num gen R".
+func (l *pdfLexer) readNumberOrRef() any {
+	n := l.readNumber()
+	if n != float64(int(n)) || n < 0 {
+		return n
+	}
+
+	save := l.pos
+	l.skipSpace()
+	if l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		gen := l.readNumber()
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == 'R' &&
+			(l.pos+1 == len(l.data) || isPDFSpace(l.data[l.pos+1]) || isPDFDelimiter(l.data[l.pos+1])) {
+			l.pos++
+			return pdfRef{num: int(n), gen: int(gen)}
+		}
+	}
+	l.pos = save
+	return n
+}
+
+func (l *pdfLexer) readNumber() float64 {
+	start := l.pos
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9' {
+			l.pos++
+			continue
+		}
+		break
+	}
+	n, _ := strconv.ParseFloat(string(l.data[start:l.pos]), 64)
+	return n
+}
+
+// pdfDocument holds every object in a file, keyed by object number.
+type pdfDocument struct {
+	objects map[int]any
+	trailer pdfDict
+}
+
+var pdfObjHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// parsePDF indexes every indirect object in data. Rather than trusting the xref
+// table, the body is scanned in file order so objects redefined by incremental
+// updates replace their earlier versions, and damaged xref tables don't matter.
+func parsePDF(data []byte) (*pdfDocument, error) {
+	doc := &pdfDocument{objects: map[int]any{}, trailer: pdfDict{}}
+	var objStreams []*pdfStream
+
+	pos := 0
+	for pos < len(data) {
+		loc := pdfObjHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		// A trailer between the previous object and this one belongs to an earlier
+		// revision; later trailers take precedence as they are merged in order.
+		doc.mergeTrailers(data[pos : pos+loc[0]])
+
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		lex := &pdfLexer{data: data, pos: pos + loc[1]}
+		obj, err := lex.readObject()
+		if err != nil && !errors.Is(err, io.EOF) {
+			pos += loc[1]
+			continue
+		}
+		doc.objects[num] = obj
+
+		if stream, ok := obj.(*pdfStream); ok {
+			switch stream.dict["Type"] {
+			case pdfName("ObjStm"):
+				objStreams = append(objStreams, stream)
+			case pdfName("XRef"):
+				doc.mergeTrailer(stream.dict)
+			}
+		}
+		pos = max(lex.pos, pos+loc[1])
+	}
+	doc.mergeTrailers(data[min(pos, len(data)):])
+
+	// Objects packed into object streams only fill gaps; a direct definition from a
+	// later revision always wins.
+	for _, stream := range objStreams {
+		doc.loadObjectStream(stream)
+	}
+
+	if len(doc.objects) == 0 {
+		return nil, fmt.Errorf("%w: no objects found", errPDFSyntax)
+	}
+	return doc, nil
+}
+
+// mergeTrailers merges every "trailer << ... >>" dictionary found in data.
+func (d *pdfDocument) mergeTrailers(data []byte) {
+	for {
+		idx := bytes.Index(data, []byte("trailer"))
+		if idx < 0 {
+			return
+		}
+		lex := &pdfLexer{data: data, pos: idx + len("trailer")}
+		if obj, err := lex.readObject(); err == nil {
+			if dict, ok := obj.(pdfDict); ok {
+				d.mergeTrailer(dict)
+			}
+		}
+		data = data[idx+len("trailer"):]
+	}
+}
+
+func (d *pdfDocument) mergeTrailer(dict pdfDict) {
+	for _, key := range []pdfName{"Root", "Encrypt", "Info"} {
+		if v, ok := dict[key]; ok {
+			d.trailer[key] = v
+		}
+	}
+}
+
+func (d *pdfDocument) loadObjectStream(stream *pdfStream) {
+	data, err := d.decodeStream(stream)
+	if err != nil {
+		return
+	}
+	n, _ := d.resolve(stream.dict["N"]).(float64)
+	first, _ := d.resolve(stream.dict["First"]).(float64)
+	// Bounds are checked as float64, so huge /N, /First or offsets can't overflow int.
+	if first < 0 || first > float64(len(data)) {
+		return
+	}
+
+	header := &pdfLexer{data: data[:int(first)]}
+	for i := 0; float64(i) < n; i++ {
+		num, err1 := header.readObject()
+		off, err2 := header.readObject()
+		if err1 != nil || err2 != nil {
+			return
+		}
+		objNum, ok1 := num.(float64)
+		objOff, ok2 := off.(float64)
+		if !ok1 || !ok2 || objOff < 0 || first+objOff >= float64(len(data)) {
+			continue
+		}
+		if _, exists := d.objects[int(objNum)]; exists {
+			continue
+		}
+		lex := &pdfLexer{data: data, pos: int(first) + int(objOff)}
+		if obj, err := lex.readObject(); err == nil {
+			d.objects[int(objNum)] = obj
+		}
+	}
+}
+
+// resolve follows indirect references, guarding against reference cycles.
+func (d *pdfDocument) resolve(obj any) any {
+	for range 32 {
+		ref, ok := obj.(pdfRef)
+		if !ok {
+			return obj
+		}
+		obj = d.objects[ref.num]
+	}
+	return nil
+}
+
+func (d *pdfDocument) dict(obj any) pdfDict {
+	switch v := d.resolve(obj).(type) {
+	case pdfDict:
+		return v
+	case *pdfStream:
+		return v.dict
+	}
+	return nil
+}
+
+func (d *pdfDocument) array(obj any) []any {
+	arr, _ := d.resolve(obj).([]any)
+	return arr
+}
+
+// decodeStream applies the stream's filters. Filters used only for images are
+// reported as errUnsupportedFilter.
+func (d *pdfDocument) decodeStream(stream *pdfStream) ([]byte, error) {
+	data := stream.raw
+
+	var filters []any
+	switch f := d.resolve(stream.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{f}
+	case []any:
+		filters = f
+	}
+
+	for _, f := range filters {
+		name, _ := d.resolve(f).(pdfName)
+		var err error
+		switch name {
+		case "FlateDecode", "Fl":
+			data, err = inflate(data)
+		case "ASCIIHexDecode", "AHx":
+			data = (&pdfLexer{data: append(append([]byte{'<'}, data...), '>')}).readHexString()
+		case "ASCII85Decode", "A85":
+			data, err = decodeASCII85(data)
+		default:
+			return nil, fmt.Errorf("%w: %s", errUnsupportedFilter, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+var errUnsupportedFilter = errors.New("unsupported PDF stream filter")
+
+// inflate decompresses zlib data, keeping whatever was recovered from a truncated
+// or slightly corrupt stream, which is common in real-world PDFs.
+func inflate(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	out, err := io.ReadAll(io.LimitReader(zr, pdfMaxDecodedStream))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	if idx := bytes.Index(data, []byte("~>")); idx >= 0 {
+		data = data[:idx]
+	}
+	out := make([]byte, 4*len(data))
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, err
+	}
+	return out[:n], nil
+}
@@ -0,0 +1,247 @@
+package ReadFunctions
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ColumnSummary describes what was found in one column of a delimited file or SQL
+// table, so a whole export can be classified without reading every detection.
+type ColumnSummary struct {
+	Column        string         `json:"column"`                // "c3" for the third column
+	Table         string         `json:"table,omitempty"`       // SQL dumps: table the column belongs to
+	Header        string         `json:"header,omitempty"`      // column name, when the file declares one
+	Values        int            `json:"values"`                // non-empty cells
+	TypeCounts    map[string]int `json:"type_counts"`           // cells holding each detection type
+	DominantType  string         `json:"dominant_type"`         // most common detection type
+	DominantRatio float64        `json:"dominant_ratio"`        // share of non-empty cells holding DominantType
+	Description   string         `json:"description,omitempty"` // "column c3 (ssn) is 98% ssn-shaped"
+}
+
+// CSVResult is what ReadCSVFile found in a delimited file.
+type CSVResult struct {
+	Detections []PIIDetection
+	Columns    []ColumnSummary // columns with at least one detection, in column order
+	Warnings   []string
+	Preview    string
+}
+
+// columnTally accumulates the counts behind a ColumnSummary.
+type columnTally struct {
+	table  string
+	header string
+	values int
+	types  map[string]int
+}
+
+// add counts one non-empty value and the detection types found in it. Document
+// keywords say nothing about the column, so they aren't counted.
+func (c *columnTally) add(detections []PIIDetection) {
+	c.values++
+	seen := map[string]bool{}
+	for _, d := range detections {
+		if d.Type != "keyword" && !seen[d.Type] {
+			seen[d.Type] = true
+			c.types[d.Type]++
+		}
+	}
+}
+
+// headerDigits rejects header rows holding data such as IDs or phone numbers.
+var headerDigits = regexp.MustCompile(`\d{3}`)
+
+// ReadCSVFile scans a CSV, TSV or similarly delimited file cell by cell. The
+// delimiter and quote character are inferred from the start of the file, a header
+// row is recognised when present, and header names are passed to the Scanner as
+// Segment.Field so they can count as context. Each detection is located by row and
+// column ("row 12 c3 (ssn)") and counted towards a per-column summary.
+//
+// Records are streamed, so memory use is bounded by the largest record rather
+// than the file size.
+func ReadCSVFile(OpenFile io.Reader) (CSVResult, error) {
+	var result CSVResult
+
+	buffered := bufio.NewReaderSize(OpenFile, 64*1024)
+	sample, err := buffered.Peek(textSniffLength)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return result, err
+	}
+	delim := csvDelimiter(string(sample))
+	if delim == 0 {
+		delim = ','
+	}
+	quote := csvQuote(string(sample), delim)
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = delim
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	var (
+		columns []*columnTally
+		preview previewBuilder
+	)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("csv parse error, stopped reading: %v", parseErr))
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+
+		for len(columns) < len(record) {
+			columns = append(columns, &columnTally{types: map[string]int{}})
+		}
+
+		if row == 1 && isHeaderRow(record, quote) {
+			for i, field := range record {
+				columns[i].header = strings.TrimSpace(unquoteField(field, quote))
+			}
+			continue
+		}
+
+		for i, field := range record {
+			field = unquoteField(field, quote)
+			preview.add(field)
+			if strings.TrimSpace(field) == "" {
+				continue
+			}
+
+			col := columns[i]
+			line, _ := reader.FieldPos(i)
+			detections, err := scanSegment(Segment{
+				Text:       field,
+				LineNumber: line,
+				Location:   csvLocation(row, i, col.header),
+				Field:      col.header,
+			})
+			if err != nil {
+				return result, err
+			}
+
+			col.add(detections)
+			result.Detections = append(result.Detections, detections...)
+		}
+	}
+
+	for i, col := range columns {
+		if summary, ok := col.summary(i); ok {
+			result.Columns = append(result.Columns, summary)
+		}
+	}
+	result.Preview = contentPreview(preview.String())
+	return result, nil
+}
+
+// csvLocation names a cell by its 1-based row and column, with the header if known.
+func csvLocation(row, index int, header string) string {
+	if header == "" {
+		return fmt.Sprintf("row %d c%d", row, index+1)
+	}
+	return fmt.Sprintf("row %d c%d (%s)", row, index+1, header)
+}
+
+// summary reports the column's detection counts, or false when nothing was found in it.
+func (c *columnTally) summary(index int) (ColumnSummary, bool) {
+	if len(c.types) == 0 {
+		return ColumnSummary{}, false
+	}
+
+	types := make([]string, 0, len(c.types))
+	for typ := range c.types {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if c.types[types[i]] != c.types[types[j]] {
+			return c.types[types[i]] > c.types[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	s := ColumnSummary{
+		Column:        fmt.Sprintf("c%d", index+1),
+		Table:         c.table,
+		Header:        c.header,
+		Values:        c.values,
+		TypeCounts:    c.types,
+		DominantType:  types[0],
+		DominantRatio: float64(c.types[types[0]]) / float64(c.values),
+	}
+
+	name := s.Column
+	switch {
+	case s.Table != "" && s.Header != "":
+		name = s.Table + "." + s.Header
+	case s.Table != "":
+		name = s.Table + "." + s.Column
+	case s.Header != "":
+		name += " (" + s.Header + ")"
+	}
+	s.Description = fmt.Sprintf("column %s is %.0f%% %s-shaped", name, s.DominantRatio*100, s.DominantType)
+	return s, true
+}
+
+// isHeaderRow guesses whether the first record names the columns: every field is a
+// distinct, non-empty label with letters in it and nothing that looks like data.
+func isHeaderRow(record []string, quote rune) bool {
+	seen := map[string]bool{}
+	for _, field := range record {
+		field = strings.ToLower(strings.TrimSpace(unquoteField(field, quote)))
+		if field == "" || seen[field] || strings.Contains(field, "@") || headerDigits.MatchString(field) {
+			return false
+		}
+		if strings.IndexFunc(field, unicode.IsLetter) < 0 {
+			return false
+		}
+		seen[field] = true
+	}
+	return len(record) > 0
+}
+
+// csvQuote infers the quote character from how fields in sample begin. encoding/csv
+// only understands double quotes; fields quoted with single quotes are unwrapped
+// afterwards by unquoteField, which can't recover a delimiter inside such a field.
+func csvQuote(sample string, delim rune) rune {
+	double, single := 0, 0
+	for _, line := range strings.Split(sample, "\n") {
+		for _, field := range strings.Split(line, string(delim)) {
+			field = strings.TrimSpace(field)
+			switch {
+			case strings.HasPrefix(field, `"`):
+				double++
+			case strings.HasPrefix(field, "'") && len(field) > 1 && strings.HasSuffix(field, "'"):
+				single++
+			}
+		}
+	}
+	if single > double {
+		return '\''
+	}
+	return '"'
+}
+
+// unquoteField strips single quotes from a field when the file uses them for quoting.
+func unquoteField(field string, quote rune) string {
+	if quote != '\'' {
+		return field
+	}
+	trimmed := strings.TrimSpace(field)
+	if len(trimmed) >= 2 && trimmed[0] == '\'' && trimmed[len(trimmed)-1] == '\'' {
+		return strings.ReplaceAll(trimmed[1:len(trimmed)-1], "''", "'")
+	}
+	return field
+}
@@ -0,0 +1,94 @@
+package ReadFunctions
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// digitScanner reports SSN-shaped digit runs as "ssn" detections, standing in for
+// RegexProcessing.ScanSegment, and records the fields it was handed.
+func digitScanner(fields map[string]string) Scanner {
+	ssn := regexp.MustCompile(`\d{3}-?\d{2}-?\d{4}`)
+	return func(seg Segment) ([]PIIDetection, error) {
+		var detections []PIIDetection
+		for _, loc := range ssn.FindAllStringIndex(seg.Text, -1) {
+			fields[seg.Location] = seg.Field
+			detections = append(detections, PIIDetection{
+				Type:        "ssn",
+				Value:       seg.Text[loc[0]:loc[1]],
+				StartOffset: seg.Offset + loc[0],
+				EndOffset:   seg.Offset + loc[1],
+				LineNumber:  seg.LineNumber,
+				Location:    seg.Location,
+			})
+		}
+		return detections, nil
+	}
+}
+
+func TestReadCSVFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		locations map[string]string // location -> field handed to the scanner
+		lines     []int
+		column    string
+	}{
+		{
+			name:      "header row",
+			input:     "name,ssn,notes\nJane,123-45-6789,ok\nJohn,987-65-4321,\"multi\nline 111-22-3333\"\n",
+			locations: map[string]string{"row 2 c2 (ssn)": "ssn", "row 3 c2 (ssn)": "ssn", "row 3 c3 (notes)": "notes"},
+			lines:     []int{2, 3, 3},
+			column:    "column c2 (ssn) is 100% ssn-shaped",
+		},
+		{
+			name:      "tab separated without header",
+			input:     "Jane\t123456789\nJohn\tnone\n",
+			locations: map[string]string{"row 1 c2": ""},
+			lines:     []int{1},
+			column:    "column c2 is 50% ssn-shaped",
+		},
+		{
+			name:      "single quoted semicolons",
+			input:     "'patient';'id'\n'Jane';'123-45-6789'\n'John';'555-12-0000'\n",
+			locations: map[string]string{"row 2 c2 (id)": "id", "row 3 c2 (id)": "id"},
+			lines:     []int{2, 3},
+			column:    "column c2 (id) is 100% ssn-shaped",
+		},
+	}
+
+	defer SetScanner(nil)
+	for _, tt := range tests {
+		fields := map[string]string{}
+		SetScanner(digitScanner(fields))
+
+		result, err := ReadCSVFile(strings.NewReader(tt.input))
+		if err != nil {
+			t.Errorf("%s: ReadCSVFile returned error: %v", tt.name, err)
+			continue
+		}
+
+		if len(result.Detections) != len(tt.lines) {
+			t.Errorf("%s: got %d detections; want %d", tt.name, len(result.Detections), len(tt.lines))
+			continue
+		}
+		for i, d := range result.Detections {
+			if d.LineNumber != tt.lines[i] {
+				t.Errorf("%s: detection %d LineNumber = %d; want %d", tt.name, i, d.LineNumber, tt.lines[i])
+			}
+			if strings.Contains(d.Value, "'") {
+				t.Errorf("%s: detection %d Value %q still quoted", tt.name, i, d.Value)
+			}
+		}
+		for loc, field := range tt.locations {
+			if got, ok := fields[loc]; !ok || got != field {
+				t.Errorf("%s: location %q field = %q (found %v); want %q", tt.name, loc, got, ok, field)
+			}
+		}
+
+		if len(result.Columns) == 0 || result.Columns[0].Description != tt.column {
+			t.Errorf("%s: column summaries = %+v; want first %q", tt.name, result.Columns, tt.column)
+		}
+	}
+}
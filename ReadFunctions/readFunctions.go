@@ -1,8 +1,11 @@
 package ReadFunctions
 
 import (
+	"archive/zip"
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -17,6 +20,7 @@ type FileAttributes struct {
 	FilePath     string    `json:"file_path"`
 	FileType     string    `json:"file_type"`
 	FileSize     int64     `json:"file_size"`
+	Encoding     string    `json:"encoding,omitempty"` // text files only: "utf-8", "utf-16le", "utf-16be", decoded before scanning
 	CreatedDate  time.Time `json:"created_date"`
 	ModifiedDate time.Time `json:"modified_date"`
 
@@ -29,11 +33,19 @@ type FileAttributes struct {
 	PIIDetections []PIIDetection `json:"pii_detections"`
 	PHIDetections []PIIDetection `json:"phi_detections"`
 
+	// Leaked credentials, with a Severity on each detection
+	SecretDetections []PIIDetection `json:"secret_detections"`
+
+	// Per-column results for delimited files and SQL dumps
+	ColumnSummaries []ColumnSummary `json:"column_summaries,omitempty"`
+
 	// Summary statistics
-	TotalPIICount   int     `json:"total_pii_count"`
-	TotalPHICount   int     `json:"total_phi_count"`
-	RiskScore       float64 `json:"risk_score"` // 0.0-1.0
-	ConfidenceScore float64 `json:"confidence_score"`
+	TotalPIICount    int         `json:"total_pii_count"`
+	TotalPHICount    int         `json:"total_phi_count"`
+	TotalSecretCount int         `json:"total_secret_count"`
+	RiskScore        float64     `json:"risk_score"`       // 0.0-1.0, see RiskModel
+	ConfidenceScore  float64     `json:"confidence_score"` // 0.0-1.0, see RiskModel
+	RiskFactors      RiskFactors `json:"risk_factors"`
 
 	// Processing status
 	Status   string   `json:"status"` // "success", "error", "partial"
@@ -41,22 +53,40 @@ type FileAttributes struct {
 	Warnings []string `json:"warnings,omitempty"`
 
 	// Content analysis
-	DocumentType   string `json:"document_type,omitempty"`   // "medical", "financial", "legal"
-	ContentPreview string `json:"content_preview,omitempty"` // First 200 chars for context
+	DocumentType           string           `json:"document_type,omitempty"`            // "medical", "financial", "legal", "hr"
+	DocumentTypeConfidence float64          `json:"document_type_confidence,omitempty"` // 0.0-1.0
+	DocumentSignals        []DocumentSignal `json:"document_signals,omitempty"`         // what DocumentType was based on, strongest first
+	ContentPreview         string           `json:"content_preview,omitempty"`          // First 200 chars for context
+
+	keywords []PIIDetection // document keywords found by the scanner, for assess
+	hidden   [][2]string    // values hidden by addDetections, with their redacted forms
+	unmasked bool           // a hidden value spans segments, so the preview can't be masked
 }
 
 type PIIDetection struct {
-	Type            string  `json:"type"`           // "ssn", "email", "phone", "name", "address"
-	Value           string  `json:"value"`          // Redacted or full value based on config
-	RedactedValue   string  `json:"redacted_value"` // "XXX-XX-1234"
-	StartOffset     int     `json:"start_offset"`
+	Type            string  `json:"type"`              // "ssn", "email", "phone", "name", "address"
+	Subtype         string  `json:"subtype,omitempty"` // refinement of Type, e.g. card brand "visa"
+	Value           string  `json:"value"`             // Redacted or full value based on config
+	RedactedValue   string  `json:"redacted_value"`    // "XXX-XX-1234"
+	StartOffset     int     `json:"start_offset"`      // in bytes of the text as read, decoded to UTF-8 when Encoding is set
 	EndOffset       int     `json:"end_offset"`
 	LineNumber      int     `json:"line_number,omitempty"`
-	Confidence      float64 `json:"confidence"`       // 0.0-1.0
-	Context         string  `json:"context"`          // Surrounding text for validation
-	DetectionMethod string  `json:"detection_method"` // "regex", "ml", "manual"
+	Location        string  `json:"location,omitempty"`   // "Sheet1!B4", "slide 3", etc. for non-line formats
+	Confidence      float64 `json:"confidence"`           // 0.0-1.0
+	Context         string  `json:"context"`              // Surrounding text for validation
+	DetectionMethod string  `json:"detection_method"`     // "regex", "ml", "manual"
+	Severity        string  `json:"severity,omitempty"`   // secrets only: "critical", "high", "medium", "low"
+	Normalized      string  `json:"normalized,omitempty"` // canonical form, e.g. E.164 "+15551234567" for phones
+	Extension       string  `json:"extension,omitempty"`  // phones only: extension digits
 }
 
+// ErrUnsupportedFileType is returned for files the readers can't extract text from.
+// Directory scans skip these rather than reporting them as failures.
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+
+// maxLineLength is the longest single line readLargeFile will accept.
+const maxLineLength = 1024 * 1024
+
 func DetectFileType(filePath string) (FileAttributes, error) {
 
 	fileAttr := FileAttributes{}
@@ -74,7 +104,7 @@ func DetectFileType(filePath string) (FileAttributes, error) {
 
 	fileSize := fileInfo.Size()
 	if fileSize <= 0 {
-		return fileAttr, fmt.Errorf("file is empty: %s", filePath)
+		return fileAttr, fmt.Errorf("%w: file is empty: %s", ErrUnsupportedFileType, filePath)
 	}
 
 	fileAttr.FilePath = filePath
@@ -82,11 +112,12 @@ func DetectFileType(filePath string) (FileAttributes, error) {
 	fileAttr.CreatedDate = fileInfo.ModTime()
 	fileAttr.ModifiedDate = fileInfo.ModTime()
 
-	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
-	if err != nil && err != io.EOF {
+	buffer := make([]byte, textSniffLength)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return fileAttr, err
 	}
+	buffer = buffer[:n]
 
 	if bytes.HasPrefix(buffer, []byte("%PDF")) {
 		fileAttr.FileType = "pdf"
@@ -97,6 +128,9 @@ func DetectFileType(filePath string) (FileAttributes, error) {
 	//like docx, xlsx, pptx or just a ZIP file
 	if bytes.HasPrefix(buffer, []byte{0x50, 0x4B, 0x03, 0x04}) {
 		fileAttr.FileType = analyzeZipContent(buffer)
+		if fileAttr.FileType == "zip" {
+			fileAttr.FileType = analyzeZipEntries(file, fileSize)
+		}
 		return fileAttr, nil
 	}
 
@@ -105,7 +139,14 @@ func DetectFileType(filePath string) (FileAttributes, error) {
 		return fileAttr, nil
 	}
 
-	return fileAttr, fmt.Errorf("unsupported file type for file: %s", filePath)
+	// No magic number, so check for text: plain, CSV, JSON or a SQL dump.
+	if fileType, encoding, ok := sniffTextType(filePath, buffer, fileSize > int64(n)); ok {
+		fileAttr.FileType = fileType
+		fileAttr.Encoding = encoding
+		return fileAttr, nil
+	}
+
+	return fileAttr, fmt.Errorf("%w for file: %s", ErrUnsupportedFileType, filePath)
 }
 
 // analyzeZipContent checks the content of a ZIP file determine if it is an office document or a generic ZIP file.
@@ -122,114 +163,214 @@ func analyzeZipContent(buffer []byte) string {
 	}
 }
 
+// analyzeZipEntries looks through the archive's central directory for the main part of
+// an office document. The first 512 bytes usually only hold [Content_Types].xml, so
+// analyzeZipContent alone misses most documents.
+func analyzeZipEntries(file io.ReaderAt, size int64) string {
+	zr, err := zip.NewReader(file, size)
+	if err != nil {
+		return "zip"
+	}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml":
+			return "docx"
+		case "xl/workbook.xml":
+			return "xlsx"
+		case "ppt/presentation.xml":
+			return "pptx"
+		}
+	}
+	return "zip"
+}
+
 func ReadFile(fileAttr FileAttributes) (FileAttributes, error) {
-	file, err := os.Open(fileAttr.FilePath)
+	return ReadFileContext(context.Background(), fileAttr)
+}
+
+// ReadFileContext is ReadFile with cancellation; the readers stop with ctx.Err() once
+// ctx is done, which lets directory scans put a time limit on each file.
+func ReadFileContext(ctx context.Context, fileAttr FileAttributes) (FileAttributes, error) {
+	osFile, err := os.Open(fileAttr.FilePath)
 	if err != nil {
 		return fileAttr, err
 	}
-	defer utilityFunctions.SafeClose(file)
+	defer utilityFunctions.SafeClose(osFile)
+
+	file := textReader(contextReader{ctx: ctx, r: osFile}, fileAttr.Encoding)
 
 	fileAttr.ProcessedAt = time.Now()
 
 	switch fileAttr.FileType {
 	case "pdf":
-		// Read PDF content (placeholder)
-		content, err := ReadPDFFile(file)
+		segments, warnings, err := ReadPDFFile(file)
+		if err != nil {
+			return fileAttr, err
+		}
+		fileAttr.Warnings = append(fileAttr.Warnings, warnings...)
+
+		detections, err := scanSegments(ctx, segments)
 		if err != nil {
 			return fileAttr, err
 		}
-		fileAttr.ContentPreview = content[:200] // First 200 chars for preview
+		fileAttr.addDetections(detections)
+		fileAttr.ProcessorUsed = "go-regex"
+		fileAttr.ContentPreview = contentPreview(segmentsText(segments)) // First 200 chars for preview
 
 	case "docx", "xlsx", "pptx":
-		// Read Office document content (placeholder)
-		content, err := ReadOfficeFile(file)
+		segments, err := ReadOfficeFile(contextReaderAt{ctx: ctx, r: osFile}, fileAttr.FileSize, fileAttr.FileType)
 		if err != nil {
 			return fileAttr, err
 		}
-		fileAttr.ContentPreview = content[:200] // First 200 chars for preview
+
+		detections, err := scanSegments(ctx, segments)
+		if err != nil {
+			return fileAttr, err
+		}
+		fileAttr.addDetections(detections)
+		fileAttr.ProcessorUsed = "go-regex"
+		fileAttr.ContentPreview = contentPreview(segmentsText(segments)) // First 200 chars for preview
 
 	case "txt":
+		var lines []string
+		var offsets []int
 		if fileAttr.FileSize > 10*1024*1024 { // 10 MB threshold for large files
-			lines, err := readLargeFile(fileAttr.FilePath)
-			if err != nil {
-				return fileAttr, err
-			} else {
-				lines, err := readInMemory(fileAttr.FilePath)
-			}
+			lines, offsets, err = readLargeFile(file)
+		} else {
+			lines, offsets, err = readInMemory(file)
+		}
+		if err != nil {
+			return fileAttr, err
 		}
 
-		return fileAttr, nil
+		detections, err := readTextLines(ctx, lines, offsets)
+		if err != nil {
+			return fileAttr, err
+		}
+		fileAttr.addDetections(detections)
+		fileAttr.ProcessorUsed = "go-regex"
+		fileAttr.ContentPreview = contentPreview(strings.Join(lines, "\n"))
 
 	case "json":
-		content, err := ReadJSONFile(file)
+		result, err := ReadJSONFile(file)
 		if err != nil {
 			return fileAttr, err
 		}
-		fileAttr.ContentPreview = fmt.Sprintf("%v", content)[:200] // First 200 chars for preview
+		fileAttr.Warnings = append(fileAttr.Warnings, result.Warnings...)
+		fileAttr.addDetections(result.Detections)
+		fileAttr.ProcessorUsed = "go-regex"
+		fileAttr.ContentPreview = result.Preview
 
 	case "csv":
-		content, err := ReadCSVFile(file)
+		result, err := ReadCSVFile(file)
 		if err != nil {
 			return fileAttr, err
 		}
-		fileAttr.ContentPreview = fmt.Sprintf("%v", content)[:200] // First 200 chars for preview
+		fileAttr.Warnings = append(fileAttr.Warnings, result.Warnings...)
+		fileAttr.addDetections(result.Detections)
+		fileAttr.ColumnSummaries = result.Columns
+		fileAttr.ProcessorUsed = "go-regex"
+		fileAttr.ContentPreview = result.Preview
 
 	case "sql":
-		content, err := ReadSQLFile(file)
+		result, err := ReadSQLFile(file)
 		if err != nil {
 			return fileAttr, err
 		}
-		fileAttr.ContentPreview = fmt.Sprintf("%v", content)[:200] // First 200 chars for preview
+		fileAttr.Warnings = append(fileAttr.Warnings, result.Warnings...)
+		fileAttr.addDetections(result.Detections)
+		fileAttr.ColumnSummaries = result.Columns
+		fileAttr.ProcessorUsed = "go-regex"
+		fileAttr.ContentPreview = result.Preview
 
 	default:
-		return fileAttr, fmt.Errorf("unsupported file type: %s", fileAttr.FileType)
+		return fileAttr, fmt.Errorf("%w: %s", ErrUnsupportedFileType, fileAttr.FileType)
+	}
+
+	fileAttr.hideValues()
+	fileAttr.assess()
+	fileAttr.ProcessingTime = time.Since(fileAttr.ProcessedAt).Milliseconds()
+	fileAttr.Status = "success"
+	if len(fileAttr.Warnings) > 0 {
+		fileAttr.Status = "partial"
 	}
 
 	return fileAttr, nil
 }
 
-func ReadPDFFile(OpenFile io.Reader) (string, error) {
-	// Implementation for reading a PDF file
-	return "", nil // Placeholder return
+// contextReader fails reads once ctx is done, so readers that consume an io.Reader
+// stop promptly when a scan is cancelled or a file runs out of time.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
 }
 
-func ReadOfficeFile(OpenFile io.Reader) (string, error) {
-	// Implementation for reading an Office file (docx, xlsx, pptx)
-	return "", nil // Placeholder return
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
 }
 
-// readLargeFile reads a large file line by line and returns its content as a slice of strings in a buffer
-func readLargeFile(filePath string) ([]string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
+// contextReaderAt is the io.ReaderAt counterpart of contextReader, for zip archives.
+type contextReaderAt struct {
+	ctx context.Context
+	r   io.ReaderAt
+}
+
+func (c contextReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
 	}
-	defer utilityFunctions.SafeClose(file)
+	return c.r.ReadAt(p, off)
+}
 
+// readLargeFile reads a large file line by line and returns its content as a slice of strings in a buffer,
+// with the byte offset in the file at which each line starts. Line endings, "\r\n" included, are stripped.
+func readLargeFile(file io.Reader) ([]string, []int, error) {
 	var lines []string
+	var offsets []int
+	offset := 0
 	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			offsets = append(offsets, offset)
+			offset += advance
+		}
+		return advance, token, err
+	})
 	for scanner.Scan() {
 		lines = append(lines, scanner.Text())
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return lines, nil
+	return lines, offsets, nil
 }
 
-// readInMemory reads a small file and returns its content as an array in memory.
-func readInMemory(filePath string) ([]string, error) {
-	data, err := os.ReadFile(filePath)
+// readInMemory reads a small file and returns its content as an array in memory, with the
+// byte offset in the file at which each line starts. Line endings, "\r\n" included, are stripped.
+func readInMemory(file io.Reader) ([]string, []int, error) {
+	data, err := io.ReadAll(file)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(data) == 0 {
-		return nil, os.ErrInvalid // Return an error if the content is empty
+		return nil, nil, os.ErrInvalid // Return an error if the content is empty
 	}
 
-	lines := strings.Split(string(data), "n")
+	lines := strings.Split(string(data), "\n")
+	offsets := make([]int, len(lines))
+	offset := 0
+	for i, line := range lines {
+		offsets[i] = offset
+		offset += len(line) + 1
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
 
-	return lines, nil
+	return lines, offsets, nil
 }
@@ -0,0 +1,207 @@
+package ReadFunctions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSONResult is what ReadJSONFile found in a JSON or NDJSON file.
+type JSONResult struct {
+	Detections []PIIDetection
+	Warnings   []string
+	Preview    string
+}
+
+// jsonFrame is an open object or array on the path to the current value.
+type jsonFrame struct {
+	array   bool
+	index   int    // arrays: index of the current element
+	key     string // objects: key of the current member
+	wantKey bool   // objects: the next token is a key
+}
+
+// ReadJSONFile scans every string and number value in a JSON document, or in a
+// stream of them such as NDJSON. Each value is located by its JSONPath
+// ("$.patients[12].ssn") and handed to the Scanner with the object keys leading to
+// it ("patients.ssn") as Segment.Field, so key names count as context.
+//
+// The document is tokenised as it is read, so memory use is bounded by the
+// nesting depth and the longest single value rather than the file size. Syntax
+// errors stop the scan with a warning, keeping what was found before them.
+func ReadJSONFile(OpenFile io.Reader) (JSONResult, error) {
+	var result JSONResult
+
+	lines := &lineCounter{r: OpenFile}
+	dec := json.NewDecoder(lines)
+	dec.UseNumber()
+
+	var (
+		stack   []jsonFrame
+		preview previewBuilder
+	)
+	// advance moves the enclosing object or array on to its next member.
+	advance := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := &stack[len(stack)-1]
+		if top.array {
+			top.index++
+		} else {
+			top.wantKey = true
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("json error at byte %d, stopped reading: %v", dec.InputOffset(), err))
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if len(stack) > 0 && stack[len(stack)-1].wantKey {
+			if key, ok := tok.(string); ok {
+				stack[len(stack)-1].key = key
+				stack[len(stack)-1].wantKey = false
+				continue
+			}
+		}
+
+		var text string
+		switch v := tok.(type) {
+		case json.Delim:
+			switch v {
+			case '{':
+				stack = append(stack, jsonFrame{wantKey: true})
+			case '[':
+				stack = append(stack, jsonFrame{array: true})
+			default:
+				stack = stack[:len(stack)-1]
+				advance()
+			}
+			continue
+		case string:
+			text = v
+		case json.Number:
+			text = v.String()
+		default: // booleans and null
+			advance()
+			continue
+		}
+
+		if text != "" {
+			preview.add(text)
+
+			detections, err := scanSegment(Segment{
+				Text:       text,
+				LineNumber: lines.lineAt(dec.InputOffset()),
+				Location:   jsonPath(stack),
+				Field:      jsonField(stack),
+			})
+			if err != nil {
+				return result, err
+			}
+			result.Detections = append(result.Detections, detections...)
+		}
+		advance()
+	}
+
+	result.Preview = contentPreview(preview.String())
+	return result, nil
+}
+
+// jsonPath renders the path to the current value as JSONPath.
+func jsonPath(stack []jsonFrame) string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, frame := range stack {
+		switch {
+		case frame.array:
+			fmt.Fprintf(&b, "[%d]", frame.index)
+		case isJSONIdentifier(frame.key):
+			b.WriteByte('.')
+			b.WriteString(frame.key)
+		default:
+			b.WriteString("['")
+			b.WriteString(strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(frame.key))
+			b.WriteString("']")
+		}
+	}
+	return b.String()
+}
+
+// jsonField joins the object keys on the path to the current value with dots,
+// leaving out array indexes: "patients.ssn" for $.patients[12].ssn.
+func jsonField(stack []jsonFrame) string {
+	var keys []string
+	for _, frame := range stack {
+		if !frame.array {
+			keys = append(keys, frame.key)
+		}
+	}
+	return strings.Join(keys, ".")
+}
+
+// isJSONIdentifier reports whether key can be written in dot notation.
+func isJSONIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// lineCounter passes reads through while noting where lines end, so a byte offset
+// reported by the JSON decoder can be turned into a line number. Only newlines
+// beyond the last offset asked about are kept, which bounds them by how far the
+// decoder reads ahead.
+type lineCounter struct {
+	r        io.Reader
+	read     int64   // bytes read so far
+	newlines []int64 // offsets of newlines not yet passed by lineAt
+	line     int     // newlines already passed
+}
+
+func (l *lineCounter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	for i := 0; i < n; {
+		j := bytes.IndexByte(p[i:n], '\n')
+		if j < 0 {
+			break
+		}
+		l.newlines = append(l.newlines, l.read+int64(i+j))
+		i += j + 1
+	}
+	l.read += int64(n)
+	return n, err
+}
+
+// lineAt returns the 1-based line holding the byte just before offset. Offsets must
+// not decrease between calls.
+func (l *lineCounter) lineAt(offset int64) int {
+	passed := 0
+	for passed < len(l.newlines) && l.newlines[passed] < offset-1 {
+		passed++
+	}
+	l.line += passed
+	l.newlines = l.newlines[passed:]
+	return l.line + 1
+}
@@ -0,0 +1,131 @@
+package ReadFunctions
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadJSONFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		fields   map[string]string // location -> field handed to the scanner
+		lines    []int
+		warnings int
+	}{
+		{
+			name: "nested document",
+			input: `{
+  "patients": [
+    {"name": "Jane", "ssn": "123-45-6789", "active": true},
+    {"name": "John", "ids": {"tax id": 987654321, "other": null}}
+  ]
+}`,
+			fields: map[string]string{
+				"$.patients[0].ssn":           "patients.ssn",
+				"$.patients[1].ids['tax id']": "patients.ids.tax id",
+			},
+			lines: []int{3, 4},
+		},
+		{
+			name:  "ndjson",
+			input: "{\"ssn\": \"111-22-3333\"}\n{\"ssn\": \"444-55-6666\"}\n[[], [\"777-88-9999\"]]\n",
+			fields: map[string]string{
+				"$.ssn":   "ssn",
+				"$[1][0]": "",
+			},
+			lines: []int{1, 2, 3},
+		},
+		{
+			name:     "truncated",
+			input:    `{"a": ["123-45-6789", "98`,
+			fields:   map[string]string{"$.a[0]": "a"},
+			lines:    []int{1},
+			warnings: 1,
+		},
+	}
+
+	defer SetScanner(nil)
+	for _, tt := range tests {
+		fields := map[string]string{}
+		SetScanner(digitScanner(fields))
+
+		result, err := ReadJSONFile(strings.NewReader(tt.input))
+		if err != nil {
+			t.Errorf("%s: ReadJSONFile returned error: %v", tt.name, err)
+			continue
+		}
+		if len(result.Warnings) != tt.warnings {
+			t.Errorf("%s: warnings = %v; want %d", tt.name, result.Warnings, tt.warnings)
+		}
+
+		var lines []int
+		for _, d := range result.Detections {
+			lines = append(lines, d.LineNumber)
+		}
+		if len(lines) != len(tt.lines) {
+			t.Errorf("%s: detections on lines %v; want %v", tt.name, lines, tt.lines)
+		} else {
+			for i := range lines {
+				if lines[i] != tt.lines[i] {
+					t.Errorf("%s: detections on lines %v; want %v", tt.name, lines, tt.lines)
+					break
+				}
+			}
+		}
+
+		for loc, field := range tt.fields {
+			if got, ok := fields[loc]; !ok || got != field {
+				t.Errorf("%s: location %q field = %q (found %v); want %q", tt.name, loc, got, ok, field)
+			}
+		}
+	}
+}
+
+// TestReadJSONFileStreams checks that paths stay right across a document far
+// larger than the decoder's buffer, fed from a generator rather than memory.
+func TestReadJSONFileStreams(t *testing.T) {
+	const records = 20000
+	r := io.MultiReader(
+		strings.NewReader(`{"rows": [`),
+		&repeatReader{s: `{"ssn": "123-45-6789", "pad": "` + strings.Repeat("x", 200) + `"},`, n: records},
+		strings.NewReader(`{}]}`),
+	)
+
+	fields := map[string]string{}
+	SetScanner(digitScanner(fields))
+	defer SetScanner(nil)
+
+	result, err := ReadJSONFile(r)
+	if err != nil {
+		t.Fatalf("ReadJSONFile returned error: %v", err)
+	}
+	if len(result.Detections) != records {
+		t.Errorf("got %d detections; want %d", len(result.Detections), records)
+	}
+	last := result.Detections[len(result.Detections)-1].Location
+	if want := "$.rows[19999].ssn"; last != want {
+		t.Errorf("last Location = %q; want %q", last, want)
+	}
+}
+
+// repeatReader yields s n times.
+type repeatReader struct {
+	s   string
+	n   int
+	pos int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.s[r.pos:])
+	r.pos += n
+	if r.pos == len(r.s) {
+		r.pos = 0
+		r.n--
+	}
+	return n, nil
+}
@@ -0,0 +1,735 @@
+package ReadFunctions
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"goScan/utilityFunctions"
+)
+
+// officePartLimit caps how much of a single decompressed part is read, so a crafted
+// archive can't expand into gigabytes of XML.
+const officePartLimit = 256 * 1024 * 1024
+
+// Relationship types used to find the parts of a package.
+const (
+	relTypeSlide      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide"
+	relTypeNotesSlide = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide"
+	relTypeComments   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments"
+	relTypeWorksheet  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet"
+)
+
+// ReadOfficeFile extracts the text of a DOCX, XLSX or PPTX package as segments whose
+// Location records where each piece of text came from: a paragraph or table cell in a
+// document part, a sheet and cell reference, or a slide number. Offsets are relative
+// to the start of each segment.
+func ReadOfficeFile(OpenFile io.ReaderAt, size int64, fileType string) ([]Segment, error) {
+	zr, err := zip.NewReader(OpenFile, size)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := map[string]*zip.File{}
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+
+	switch fileType {
+	case "docx":
+		return readWordDocument(parts)
+	case "xlsx":
+		return readWorkbook(parts)
+	case "pptx":
+		return readPresentation(parts)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, fileType)
+	}
+}
+
+// readWordDocument extracts the body, headers, footers, footnotes, endnotes and
+// comments of a DOCX, including text from tracked insertions and deletions.
+func readWordDocument(parts map[string]*zip.File) ([]Segment, error) {
+	names := []string{"word/document.xml"}
+	var extras []string
+	for name := range parts {
+		dir, file := path.Split(name)
+		if dir != "word/" || !strings.HasSuffix(file, ".xml") {
+			continue
+		}
+		for _, prefix := range []string{"header", "footer", "footnotes", "endnotes", "comments"} {
+			if strings.HasPrefix(file, prefix) {
+				extras = append(extras, name)
+				break
+			}
+		}
+	}
+	sort.Strings(extras)
+	names = append(names, extras...)
+
+	var segments []Segment
+	for _, name := range names {
+		f, ok := parts[name]
+		if !ok {
+			continue
+		}
+		found, err := readParagraphs(f, path.Base(name))
+		if err != nil {
+			return segments, fmt.Errorf("%s: %w", name, err)
+		}
+		segments = append(segments, found...)
+	}
+	return segments, nil
+}
+
+// readPresentation extracts slide text, tables, speaker notes and comments from a PPTX
+// in presentation order.
+func readPresentation(parts map[string]*zip.File) ([]Segment, error) {
+	slides, err := relatedParts(parts, "ppt/presentation.xml", relTypeSlide)
+	if err != nil {
+		return nil, err
+	}
+	if len(slides) == 0 {
+		slides = partsMatching(parts, "ppt/slides/slide")
+	}
+
+	var segments []Segment
+	for i, slide := range slides {
+		label := "slide " + strconv.Itoa(i+1)
+		found, err := readParagraphs(parts[slide], label)
+		if err != nil {
+			return segments, fmt.Errorf("%s: %w", slide, err)
+		}
+		segments = append(segments, found...)
+
+		notes, err := relatedParts(parts, slide, relTypeNotesSlide)
+		if err != nil {
+			return segments, err
+		}
+		for _, name := range notes {
+			found, err := readParagraphs(parts[name], label+" notes")
+			if err != nil {
+				return segments, fmt.Errorf("%s: %w", name, err)
+			}
+			segments = append(segments, found...)
+		}
+
+		comments, err := relatedParts(parts, slide, relTypeComments)
+		if err != nil {
+			return segments, err
+		}
+		for _, name := range comments {
+			found, err := readElementText(parts[name], "text", label+" comment")
+			if err != nil {
+				return segments, fmt.Errorf("%s: %w", name, err)
+			}
+			segments = append(segments, found...)
+		}
+	}
+	return segments, nil
+}
+
+// tablePosition tracks the current cell while walking a (possibly nested) table.
+type tablePosition struct {
+	table, row, cell int
+}
+
+// paragraphState collects the text of one paragraph. Deleted text from tracked
+// changes is kept apart so it can be labelled as such.
+type paragraphState struct {
+	number        int
+	text, deleted strings.Builder
+}
+
+// readParagraphs walks a WordprocessingML or DrawingML part and returns one segment
+// per paragraph. Both vocabularies use the local names p, t, tbl, tr and tc, so the
+// same walk serves documents, slides and notes.
+func readParagraphs(f *zip.File, label string) ([]Segment, error) {
+	rc, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer utilityFunctions.SafeClose(rc)
+
+	var segments []Segment
+	var paragraphs []*paragraphState
+	var tables []tablePosition
+	var textTarget *strings.Builder
+	paragraph, tableCount := 0, 0
+	comment := ""
+
+	location := func(paragraph int) string {
+		loc := label
+		if comment != "" {
+			loc += " comment " + comment
+		}
+		if len(tables) > 0 {
+			t := tables[len(tables)-1]
+			return fmt.Sprintf("%s table %d row %d cell %d", loc, t.table, t.row, t.cell)
+		}
+		return fmt.Sprintf("%s paragraph %d", loc, paragraph)
+	}
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return segments, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var current *paragraphState
+			if len(paragraphs) > 0 {
+				current = paragraphs[len(paragraphs)-1]
+			}
+
+			switch t.Name.Local {
+			case "p":
+				paragraph++
+				paragraphs = append(paragraphs, &paragraphState{number: paragraph})
+			case "t":
+				if current != nil {
+					textTarget = &current.text
+				}
+			case "delText":
+				if current != nil {
+					textTarget = &current.deleted
+				}
+			case "tab":
+				if current != nil {
+					current.text.WriteByte('\t')
+				}
+			case "br", "cr":
+				if current != nil {
+					current.text.WriteByte(' ')
+				}
+			case "tbl":
+				tableCount++
+				tables = append(tables, tablePosition{table: tableCount})
+			case "tr":
+				if len(tables) > 0 {
+					tables[len(tables)-1].row++
+					tables[len(tables)-1].cell = 0
+				}
+			case "tc":
+				if len(tables) > 0 {
+					tables[len(tables)-1].cell++
+				}
+			case "comment":
+				comment = attrValue(t, "id")
+				if author := attrValue(t, "author"); author != "" {
+					comment += " by " + author
+				}
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t", "delText":
+				textTarget = nil
+			case "p":
+				if len(paragraphs) == 0 {
+					continue
+				}
+				p := paragraphs[len(paragraphs)-1]
+				paragraphs = paragraphs[:len(paragraphs)-1]
+
+				loc := location(p.number)
+				if text := strings.TrimSpace(p.text.String()); text != "" {
+					segments = append(segments, Segment{Text: text, Location: loc})
+				}
+				if deleted := strings.TrimSpace(p.deleted.String()); deleted != "" {
+					segments = append(segments, Segment{Text: deleted, Location: loc + " (deleted)"})
+				}
+			case "tbl":
+				if len(tables) > 0 {
+					tables = tables[:len(tables)-1]
+				}
+			case "comment":
+				comment = ""
+			}
+
+		case xml.CharData:
+			if textTarget != nil {
+				textTarget.Write(t)
+			}
+		}
+	}
+
+	return segments, nil
+}
+
+// readWorkbook extracts every cell value (resolving shared strings) and cell comment
+// from an XLSX, located as Sheet!Cell.
+func readWorkbook(parts map[string]*zip.File) ([]Segment, error) {
+	shared, err := readSharedStrings(parts["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, fmt.Errorf("xl/sharedStrings.xml: %w", err)
+	}
+
+	sheets, err := workbookSheets(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []Segment
+	for _, sheet := range sheets {
+		found, err := readWorksheet(parts[sheet.part], sheet.name, shared)
+		if err != nil {
+			return segments, fmt.Errorf("%s: %w", sheet.part, err)
+		}
+		segments = append(segments, found...)
+
+		comments, err := relatedParts(parts, sheet.part, relTypeComments)
+		if err != nil {
+			return segments, err
+		}
+		for _, name := range comments {
+			found, err := readCellComments(parts[name], sheet.name)
+			if err != nil {
+				return segments, fmt.Errorf("%s: %w", name, err)
+			}
+			segments = append(segments, found...)
+		}
+	}
+	return segments, nil
+}
+
+type worksheet struct {
+	name, part string
+}
+
+// workbookSheets lists the sheets in workbook order with the part holding each one.
+func workbookSheets(parts map[string]*zip.File) ([]worksheet, error) {
+	rels, err := readRelationships(parts, "xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := parts["xl/workbook.xml"]
+	if !ok {
+		var sheets []worksheet
+		for i, name := range partsMatching(parts, "xl/worksheets/sheet") {
+			sheets = append(sheets, worksheet{name: "Sheet" + strconv.Itoa(i+1), part: name})
+		}
+		return sheets, nil
+	}
+
+	rc, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer utilityFunctions.SafeClose(rc)
+
+	var sheets []worksheet
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("xl/workbook.xml: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "sheet" {
+			continue
+		}
+		rel, ok := rels[relationshipID(start)]
+		if !ok || rel.relType != relTypeWorksheet {
+			continue
+		}
+		if _, ok := parts[rel.target]; ok {
+			sheets = append(sheets, worksheet{name: attrValue(start, "name"), part: rel.target})
+		}
+	}
+	return sheets, nil
+}
+
+// readSharedStrings returns the shared string table, skipping phonetic runs.
+func readSharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer utilityFunctions.SafeClose(rc)
+
+	var strs []string
+	var current strings.Builder
+	inText, inPhonetic := false, false
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return strs, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = !inPhonetic
+			case "rPh":
+				inPhonetic = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				strs = append(strs, current.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonetic = false
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+	return strs, nil
+}
+
+// readWorksheet returns one segment per non-empty text or numeric cell.
+func readWorksheet(f *zip.File, sheetName string, shared []string) ([]Segment, error) {
+	rc, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer utilityFunctions.SafeClose(rc)
+
+	var segments []Segment
+	var value strings.Builder
+	var ref, cellType string
+	inValue := false
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return segments, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "c":
+				ref, cellType = attrValue(t, "r"), attrValue(t, "t")
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				if text := cellText(value.String(), cellType, shared); text != "" {
+					segments = append(segments, Segment{Text: text, Location: sheetName + "!" + ref})
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+	return segments, nil
+}
+
+// cellText converts a raw cell value to the text a user would see. Numbers stored in
+// exponent form (a nine digit SSN saved as 1.23456789E8) are expanded back to digits.
+func cellText(raw, cellType string, shared []string) string {
+	raw = strings.TrimSpace(raw)
+	switch cellType {
+	case "s":
+		idx, err := strconv.Atoi(raw)
+		if err != nil || idx < 0 || idx >= len(shared) {
+			return ""
+		}
+		return strings.TrimSpace(shared[idx])
+	case "b", "e":
+		return ""
+	case "", "n":
+		if strings.ContainsAny(raw, "eE") {
+			if n, err := strconv.ParseFloat(raw, 64); err == nil && n == float64(int64(n)) {
+				return strconv.FormatInt(int64(n), 10)
+			}
+		}
+		return raw
+	default: // "str" formula results and "inlineStr"
+		return raw
+	}
+}
+
+// readCellComments returns one segment per cell comment, located on its cell.
+func readCellComments(f *zip.File, sheetName string) ([]Segment, error) {
+	rc, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer utilityFunctions.SafeClose(rc)
+
+	var segments []Segment
+	var text strings.Builder
+	var ref string
+	inText := false
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return segments, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "comment":
+				ref = attrValue(t, "ref")
+				text.Reset()
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "comment":
+				if s := strings.TrimSpace(text.String()); s != "" {
+					segments = append(segments, Segment{Text: s, Location: sheetName + "!" + ref + " comment"})
+				}
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+	return segments, nil
+}
+
+type relationship struct {
+	relType, target string
+}
+
+// readRelationships parses the .rels part that belongs to partName, resolving each
+// target to a part name within the package.
+func readRelationships(parts map[string]*zip.File, partName string) (map[string]relationship, error) {
+	dir, file := path.Split(partName)
+	relsName := dir + "_rels/" + file + ".rels"
+
+	f, ok := parts[relsName]
+	if !ok {
+		return nil, nil
+	}
+	rc, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer utilityFunctions.SafeClose(rc)
+
+	rels := map[string]relationship{}
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", relsName, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Relationship" || attrValue(start, "TargetMode") == "External" {
+			continue
+		}
+
+		target := attrValue(start, "Target")
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(dir, target)
+		}
+		rels[attrValue(start, "Id")] = relationship{relType: attrValue(start, "Type"), target: target}
+	}
+	return rels, nil
+}
+
+// relatedParts returns the parts related to partName with the given relationship
+// type, ordered as they are referenced when partName is ppt/presentation.xml.
+func relatedParts(parts map[string]*zip.File, partName, relType string) ([]string, error) {
+	rels, err := readRelationships(parts, partName)
+	if err != nil {
+		return nil, err
+	}
+
+	if relType == relTypeSlide {
+		return slideOrder(parts, partName, rels)
+	}
+
+	var names []string
+	for _, rel := range rels {
+		if _, ok := parts[rel.target]; ok && rel.relType == relType {
+			names = append(names, rel.target)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// slideOrder reads the slide id list of a presentation, which is the order the slides
+// are shown in regardless of how the slide parts are named.
+func slideOrder(parts map[string]*zip.File, partName string, rels map[string]relationship) ([]string, error) {
+	f, ok := parts[partName]
+	if !ok {
+		return nil, nil
+	}
+	rc, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer utilityFunctions.SafeClose(rc)
+
+	var slides []string
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", partName, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "sldId" {
+			continue
+		}
+		rel, ok := rels[relationshipID(start)]
+		if !ok || rel.relType != relTypeSlide {
+			continue
+		}
+		if _, ok := parts[rel.target]; ok {
+			slides = append(slides, rel.target)
+		}
+	}
+	return slides, nil
+}
+
+// partsMatching lists the XML parts starting with prefix in natural order (slide2
+// before slide10), used when a package is missing its relationship parts.
+func partsMatching(parts map[string]*zip.File, prefix string) []string {
+	var names []string
+	for name := range parts {
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".xml") {
+			names = append(names, name)
+		}
+	}
+	number := func(name string) int {
+		n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".xml"))
+		return n
+	}
+	sort.Slice(names, func(i, j int) bool { return number(names[i]) < number(names[j]) })
+	return names
+}
+
+// limitedPart closes the part while reading at most officePartLimit bytes from it.
+type limitedPart struct {
+	io.Reader
+	io.Closer
+}
+
+func openPart(f *zip.File) (io.ReadCloser, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return limitedPart{Reader: io.LimitReader(rc, officePartLimit), Closer: rc}, nil
+}
+
+// attrValue returns the value of the attribute with the given local name, ignoring
+// its namespace.
+func attrValue(start xml.StartElement, local string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// relationshipID returns the r:id attribute of start, which points into the part's
+// relationships. It is distinguished from a plain id attribute by its namespace.
+func relationshipID(start xml.StartElement) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "id" && strings.HasSuffix(attr.Name.Space, "/relationships") {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// readElementText returns one segment per occurrence of the named element, labelled
+// with its position. PowerPoint comments keep their text in p:text rather than paragraphs.
+func readElementText(f *zip.File, element, label string) ([]Segment, error) {
+	rc, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer utilityFunctions.SafeClose(rc)
+
+	var segments []Segment
+	var text strings.Builder
+	inText, count := false, 0
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return segments, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == element {
+				inText = true
+				count++
+				text.Reset()
+			}
+		case xml.EndElement:
+			if t.Name.Local == element {
+				inText = false
+				if s := strings.TrimSpace(text.String()); s != "" {
+					segments = append(segments, Segment{Text: s, Location: label + " " + strconv.Itoa(count)})
+				}
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+	return segments, nil
+}
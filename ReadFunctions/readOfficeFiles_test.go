@@ -0,0 +1,148 @@
+package ReadFunctions
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+const (
+	wordNS  = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	relNS   = `xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	relsXML = `<?xml version="1.0"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+)
+
+func buildPackage(t *testing.T, parts map[string]string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+// segmentsByLocation indexes extracted segments for assertions.
+func segmentsByLocation(segments []Segment) map[string]string {
+	found := map[string]string{}
+	for _, seg := range segments {
+		found[seg.Location] = seg.Text
+	}
+	return found
+}
+
+func TestReadOfficeFileDocx(t *testing.T) {
+	pkg := buildPackage(t, map[string]string{
+		"word/document.xml": `<w:document ` + wordNS + `><w:body>
+			<w:p><w:r><w:t>Patient: Mary Johnson</w:t></w:r></w:p>
+			<w:p><w:ins><w:r><w:t>SSN 123-45-6789</w:t></w:r></w:ins><w:del><w:r><w:delText>987-65-4321</w:delText></w:r></w:del></w:p>
+			<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Email</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>mary@example.com</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+		</w:body></w:document>`,
+		"word/header1.xml":  `<w:hdr ` + wordNS + `><w:p><w:r><w:t>Confidential</w:t></w:r></w:p></w:hdr>`,
+		"word/comments.xml": `<w:comments ` + wordNS + `><w:comment w:id="0" w:author="HR"><w:p><w:r><w:t>DOB 01/02/1960</w:t></w:r></w:p></w:comment></w:comments>`,
+	})
+
+	segments, err := ReadOfficeFile(pkg, pkg.Size(), "docx")
+	if err != nil {
+		t.Fatalf("ReadOfficeFile returned error: %v", err)
+	}
+
+	found := segmentsByLocation(segments)
+	want := map[string]string{
+		"document.xml paragraph 1":                 "Patient: Mary Johnson",
+		"document.xml paragraph 2":                 "SSN 123-45-6789",
+		"document.xml paragraph 2 (deleted)":       "987-65-4321",
+		"document.xml table 1 row 1 cell 2":        "mary@example.com",
+		"header1.xml paragraph 1":                  "Confidential",
+		"comments.xml comment 0 by HR paragraph 1": "DOB 01/02/1960",
+	}
+	for loc, text := range want {
+		if found[loc] != text {
+			t.Errorf("segment at %q = %q; want %q (all: %v)", loc, found[loc], text, found)
+		}
+	}
+}
+
+func TestReadOfficeFileXlsx(t *testing.T) {
+	pkg := buildPackage(t, map[string]string{
+		"xl/workbook.xml": `<workbook ` + relNS + `><sheets>
+			<sheet name="Employees" sheetId="1" r:id="rId1"/>
+		</sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": relsXML +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>ssn</t></si><si><r><t>jane</t></r><r><t>@example.com</t></r><rPh><t>ignored</t></rPh></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+			<row r="2"><c r="A2"><v>1.23456789E8</v></c><c r="B2" t="inlineStr"><is><t>inline text</t></is></c><c r="C2" t="b"><v>1</v></c></row>
+		</sheetData></worksheet>`,
+		"xl/worksheets/_rels/sheet1.xml.rels": relsXML +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments" Target="../comments1.xml"/></Relationships>`,
+		"xl/comments1.xml": `<comments><commentList><comment ref="A2"><text><r><t>verify SSN</t></r></text></comment></commentList></comments>`,
+	})
+
+	segments, err := ReadOfficeFile(pkg, pkg.Size(), "xlsx")
+	if err != nil {
+		t.Fatalf("ReadOfficeFile returned error: %v", err)
+	}
+
+	found := segmentsByLocation(segments)
+	want := map[string]string{
+		"Employees!A1":         "ssn",
+		"Employees!B1":         "jane@example.com",
+		"Employees!A2":         "123456789",
+		"Employees!B2":         "inline text",
+		"Employees!A2 comment": "verify SSN",
+	}
+	for loc, text := range want {
+		if found[loc] != text {
+			t.Errorf("segment at %q = %q; want %q (all: %v)", loc, found[loc], text, found)
+		}
+	}
+	if _, ok := found["Employees!C2"]; ok {
+		t.Errorf("boolean cell C2 should not be extracted")
+	}
+}
+
+func TestReadOfficeFilePptx(t *testing.T) {
+	slideRel := "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide"
+	pkg := buildPackage(t, map[string]string{
+		"ppt/presentation.xml": `<p:presentation xmlns:p="p" ` + relNS + `><p:sldIdLst>
+			<p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId2"/>
+		</p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": relsXML +
+			`<Relationship Id="rId2" Type="` + slideRel + `" Target="slides/slide1.xml"/>` +
+			`<Relationship Id="rId3" Type="` + slideRel + `" Target="slides/slide2.xml"/></Relationships>`,
+		"ppt/slides/slide1.xml": `<p:sld xmlns:p="p" xmlns:a="a"><a:p><a:r><a:t>Second slide</a:t></a:r></a:p></p:sld>`,
+		"ppt/slides/slide2.xml": `<p:sld xmlns:p="p" xmlns:a="a"><a:p><a:r><a:t>First slide</a:t></a:r></a:p></p:sld>`,
+		"ppt/slides/_rels/slide2.xml.rels": relsXML +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" Target="../notesSlides/notesSlide1.xml"/></Relationships>`,
+		"ppt/notesSlides/notesSlide1.xml": `<p:notes xmlns:p="p" xmlns:a="a"><a:p><a:r><a:t>Call 555-123-4567</a:t></a:r></a:p></p:notes>`,
+	})
+
+	segments, err := ReadOfficeFile(pkg, pkg.Size(), "pptx")
+	if err != nil {
+		t.Fatalf("ReadOfficeFile returned error: %v", err)
+	}
+
+	found := segmentsByLocation(segments)
+	want := map[string]string{
+		"slide 1 paragraph 1":       "First slide",
+		"slide 2 paragraph 1":       "Second slide",
+		"slide 1 notes paragraph 1": "Call 555-123-4567",
+	}
+	for loc, text := range want {
+		if found[loc] != text {
+			t.Errorf("segment at %q = %q; want %q (all: %v)", loc, found[loc], text, found)
+		}
+	}
+}
@@ -0,0 +1,625 @@
+package ReadFunctions
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// pdfMaxFormDepth limits how deeply form XObjects are followed from a page.
+const pdfMaxFormDepth = 8
+
+// ReadPDFFile extracts the text of every page as segments located by page number,
+// with offsets relative to the start of that page's text. Content streams may be
+// compressed with FlateDecode, and fonts with a ToUnicode CMap are mapped back to
+// Unicode. Objects are read in file order, so incremental updates are honoured.
+//
+// Encrypted and image-only PDFs are not an error: the returned warnings explain why
+// no text was extracted, and the caller reports the file as partial.
+func ReadPDFFile(OpenFile io.Reader) ([]Segment, []string, error) {
+	data, err := io.ReadAll(OpenFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, encrypted := doc.trailer["Encrypt"]; encrypted {
+		return nil, []string{"PDF is encrypted, text was not extracted"}, nil
+	}
+
+	pages := doc.pages()
+	if len(pages) == 0 {
+		return nil, []string{"PDF has no readable page tree, text was not extracted"}, nil
+	}
+
+	var segments []Segment
+	ex := &pdfExtractor{doc: doc, fonts: map[pdfRef]*pdfFont{}}
+	images := false
+	for i, page := range pages {
+		text, hasImages := ex.pageText(page)
+		images = images || hasImages
+
+		offset := 0
+		for _, line := range strings.Split(text, "\n") {
+			if trimmed := strings.TrimSpace(line); trimmed != "" {
+				segments = append(segments, Segment{Text: line, Offset: offset, Location: "page " + strconv.Itoa(i+1)})
+			}
+			offset += len(line) + 1
+		}
+	}
+
+	var warnings []string
+	if len(segments) == 0 && images {
+		warnings = append(warnings, "PDF has no extractable text and appears to be image-only, OCR is not supported")
+	}
+	if ex.undecodable > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d text runs use fonts without a ToUnicode map and could not be decoded", ex.undecodable))
+	}
+	if ex.unsupported > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d content streams use unsupported filters and were skipped", ex.unsupported))
+	}
+	return segments, warnings, nil
+}
+
+// pdfPage is a leaf of the page tree with its inherited resources.
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages walks the page tree from the document catalog in display order. When the
+// catalog is missing or broken, every /Type /Page object is used in object order.
+func (d *pdfDocument) pages() []pdfPage {
+	var pages []pdfPage
+	visited := map[any]bool{}
+
+	var walk func(node any, resources pdfDict)
+	walk = func(node any, resources pdfDict) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		dict := d.dict(node)
+		if dict == nil {
+			return
+		}
+		if r := d.dict(dict["Resources"]); r != nil {
+			resources = r
+		}
+		if dict["Type"] == pdfName("Page") || dict["Kids"] == nil {
+			pages = append(pages, pdfPage{dict: dict, resources: resources})
+			return
+		}
+		for _, kid := range d.array(dict["Kids"]) {
+			walk(kid, resources)
+		}
+	}
+
+	if catalog := d.dict(d.trailer["Root"]); catalog != nil {
+		walk(catalog["Pages"], nil)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	maxNum := 0
+	for num := range d.objects {
+		maxNum = max(maxNum, num)
+	}
+	for num := 0; num <= maxNum; num++ {
+		if dict := d.dict(d.objects[num]); dict != nil && dict["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{dict: dict, resources: d.dict(dict["Resources"])})
+		}
+	}
+	return pages
+}
+
+// pdfExtractor turns page content streams into text, caching decoded fonts.
+type pdfExtractor struct {
+	doc         *pdfDocument
+	fonts       map[pdfRef]*pdfFont
+	forms       map[pdfRef]bool // form XObjects already drawn on the current page
+	undecodable int
+	unsupported int
+}
+
+// pageText returns the text of a page with line breaks where the text position
+// moves to a new line, and whether the page draws any images.
+func (ex *pdfExtractor) pageText(page pdfPage) (string, bool) {
+	var content []byte
+	contents := ex.doc.resolve(page.dict["Contents"])
+	streams := []any{contents}
+	if arr, ok := contents.([]any); ok {
+		streams = arr
+	}
+	for _, s := range streams {
+		stream, ok := ex.doc.resolve(s).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := ex.doc.decodeStream(stream)
+		if err != nil {
+			ex.unsupported++
+			continue
+		}
+		content = append(content, data...)
+		content = append(content, '\n')
+	}
+
+	var out strings.Builder
+	ex.forms = map[pdfRef]bool{}
+	images := ex.runContent(content, page.resources, &out, 0)
+	return out.String(), images
+}
+
+// runContent interprets the text operators of a content stream, appending text to
+// out, and reports whether the stream paints images. Only the vertical text position
+// is tracked: text shown at a new baseline starts a new line, and text moved along
+// the same baseline is separated by a space.
+func (ex *pdfExtractor) runContent(content []byte, resources pdfDict, out *strings.Builder, depth int) bool {
+	lex := &pdfLexer{data: content}
+	var operands []any
+	var font *pdfFont
+	images := false
+
+	y, leading := 0.0, 0.0
+	shownY := math.NaN()
+	pendingSpace := false
+
+	number := func(i int) float64 {
+		if i < len(operands) {
+			n, _ := operands[i].(float64)
+			return n
+		}
+		return 0
+	}
+	show := func(s []byte) {
+		if font == nil {
+			font = &pdfFont{}
+		}
+		text, ok := font.decode(s)
+		if !ok {
+			ex.undecodable++
+			return
+		}
+		if text == "" {
+			return
+		}
+
+		ends := func(suffix string) bool { return strings.HasSuffix(out.String(), suffix) }
+		switch {
+		case out.Len() == 0:
+		case !math.IsNaN(shownY) && math.Abs(y-shownY) > 1:
+			if !ends("\n") {
+				out.WriteByte('\n')
+			}
+		case pendingSpace && !ends(" ") && !ends("\n"):
+			out.WriteByte(' ')
+		}
+		out.WriteString(text)
+		shownY, pendingSpace = y, false
+	}
+	lastString := func() []byte {
+		if len(operands) == 0 {
+			return nil
+		}
+		s, _ := operands[len(operands)-1].([]byte)
+		return s
+	}
+
+	for {
+		obj, err := lex.readObject()
+		if err != nil {
+			break
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "BT":
+			y, pendingSpace = 0, true
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[0].(pdfName); ok {
+					font = ex.font(resources, name)
+				}
+			}
+		case "TL":
+			leading = number(0)
+		case "Td", "TD":
+			y += number(1)
+			if op == "TD" {
+				leading = -number(1)
+			}
+			if number(1) == 0 && number(0) != 0 {
+				pendingSpace = true
+			}
+		case "Tm":
+			y, pendingSpace = number(5), true
+		case "T*":
+			y -= leading
+		case "Tj":
+			show(lastString())
+		case "'", "\"":
+			y -= leading
+			show(lastString())
+		case "TJ":
+			if len(operands) >= 1 {
+				arr, _ := operands[len(operands)-1].([]any)
+				for _, item := range arr {
+					switch v := item.(type) {
+					case []byte:
+						show(v)
+					case float64:
+						// Large negative adjustments are how many producers encode a space.
+						if v < -200 {
+							pendingSpace = true
+						}
+					}
+				}
+			}
+		case "BI":
+			lex.pos = skipInlineImage(content, lex.pos)
+			images = true
+		case "Do":
+			if len(operands) == 0 {
+				break
+			}
+			if name, ok := operands[0].(pdfName); ok {
+				if out.Len() > 0 && !strings.HasSuffix(out.String(), "\n") {
+					out.WriteByte('\n')
+				}
+				images = ex.runXObject(resources, name, out, depth) || images
+				shownY = math.NaN()
+			}
+		}
+		operands = operands[:0]
+	}
+	return images
+}
+
+// skipInlineImage returns the position just past the EI operator that ends the
+// binary data of an inline image starting at pos.
+func skipInlineImage(content []byte, pos int) int {
+	for i := pos; i+1 < len(content); i++ {
+		if content[i] != 'E' || content[i+1] != 'I' {
+			continue
+		}
+		before := i == 0 || isPDFSpace(content[i-1])
+		after := i+2 == len(content) || isPDFSpace(content[i+2])
+		if before && after {
+			return i + 2
+		}
+	}
+	return len(content)
+}
+
+// runXObject follows a Do operator into a form XObject, reporting whether it is, or
+// contains, an image. Each form is drawn at most once per page, so forms that draw
+// themselves, or the same form many times over, can't loop or multiply the work.
+func (ex *pdfExtractor) runXObject(resources pdfDict, name pdfName, out *strings.Builder, depth int) bool {
+	xobjects := ex.doc.dict(resources["XObject"])
+	if ref, ok := xobjects[name].(pdfRef); ok {
+		if ex.forms[ref] {
+			return false
+		}
+		ex.forms[ref] = true
+	}
+	stream, ok := ex.doc.resolve(xobjects[name]).(*pdfStream)
+	if !ok {
+		return false
+	}
+
+	switch stream.dict["Subtype"] {
+	case pdfName("Image"):
+		return true
+	case pdfName("Form"):
+		if depth >= pdfMaxFormDepth {
+			return false
+		}
+		data, err := ex.doc.decodeStream(stream)
+		if err != nil {
+			ex.unsupported++
+			return false
+		}
+		formResources := ex.doc.dict(stream.dict["Resources"])
+		if formResources == nil {
+			formResources = resources
+		}
+		return ex.runContent(data, formResources, out, depth+1)
+	}
+	return false
+}
+
+// font loads the named font from resources, caching it by object reference.
+func (ex *pdfExtractor) font(resources pdfDict, name pdfName) *pdfFont {
+	ref, isRef := ex.doc.dict(resources["Font"])[name].(pdfRef)
+	if isRef {
+		if f, ok := ex.fonts[ref]; ok {
+			return f
+		}
+	}
+
+	dict := ex.doc.dict(ex.doc.dict(resources["Font"])[name])
+	f := &pdfFont{composite: dict["Subtype"] == pdfName("Type0")}
+	if stream, ok := ex.doc.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, err := ex.doc.decodeStream(stream); err == nil {
+			f.toUnicode = parseCMap(data)
+		}
+	}
+	if !f.composite {
+		f.differences = ex.differences(dict["Encoding"])
+	}
+
+	if isRef {
+		ex.fonts[ref] = f
+	}
+	return f
+}
+
+// differences reads the /Differences array of a simple font's encoding dictionary.
+func (ex *pdfExtractor) differences(encoding any) map[byte]string {
+	enc := ex.doc.dict(encoding)
+	if enc == nil {
+		return nil
+	}
+	diffs := map[byte]string{}
+	code := 0
+	for _, item := range ex.doc.array(enc["Differences"]) {
+		switch v := item.(type) {
+		case float64:
+			code = int(v)
+		case pdfName:
+			if code >= 0 && code < 256 {
+				if r, ok := glyphRune(string(v)); ok {
+					diffs[byte(code)] = r
+				}
+			}
+			code++
+		}
+	}
+	return diffs
+}
+
+// pdfFont decodes the strings passed to text-showing operators.
+type pdfFont struct {
+	composite   bool
+	toUnicode   *pdfCMap
+	differences map[byte]string
+}
+
+// decode maps character codes to text. Composite fonts without a ToUnicode map use
+// glyph ids that can't be turned back into text, so ok is false for them.
+func (f *pdfFont) decode(s []byte) (string, bool) {
+	if f.toUnicode != nil {
+		return f.toUnicode.decode(s, f.composite), true
+	}
+	if f.composite {
+		return "", false
+	}
+
+	var b strings.Builder
+	for _, c := range s {
+		if r, ok := f.differences[c]; ok {
+			b.WriteString(r)
+			continue
+		}
+		b.WriteRune(winAnsiRune(c))
+	}
+	return b.String(), true
+}
+
+// winAnsiRune maps a byte in the standard Latin text encoding to Unicode. The
+// encodings differ from Latin-1 mainly in the 0x80-0x9F range, of which only the
+// punctuation that matters for text matching is mapped.
+func winAnsiRune(c byte) rune {
+	switch c {
+	case 0x91, 0x92:
+		return '\''
+	case 0x93, 0x94:
+		return '"'
+	case 0x96, 0x97:
+		return '-'
+	case 0x95:
+		return '•'
+	}
+	if c < 0x20 && c != '\t' {
+		return ' '
+	}
+	return rune(c)
+}
+
+// glyphNames covers the glyph names that appear in /Differences for ordinary text.
+var glyphNames = map[string]string{
+	"space": " ", "exclam": "!", "quotedbl": "\"", "numbersign": "#", "dollar": "$",
+	"percent": "%", "ampersand": "&", "quotesingle": "'", "quoteright": "'", "quoteleft": "'",
+	"parenleft": "(", "parenright": ")", "asterisk": "*", "plus": "+", "comma": ",",
+	"hyphen": "-", "minus": "-", "endash": "-", "emdash": "-", "period": ".", "slash": "/",
+	"zero": "0", "one": "1", "two": "2", "three": "3", "four": "4", "five": "5",
+	"six": "6", "seven": "7", "eight": "8", "nine": "9", "colon": ":", "semicolon": ";",
+	"less": "<", "equal": "=", "greater": ">", "question": "?", "at": "@",
+	"bracketleft": "[", "backslash": "\\", "bracketright": "]", "underscore": "_",
+	"braceleft": "{", "bar": "|", "braceright": "}", "asciitilde": "~",
+	"quotedblleft": "\"", "quotedblright": "\"", "bullet": "•",
+	"fi": "fi", "fl": "fl", "ff": "ff", "ffi": "ffi", "ffl": "ffl",
+}
+
+// glyphRune resolves a glyph name to text: single letters name themselves, uniXXXX
+// names carry the code point, and the rest come from glyphNames.
+func glyphRune(name string) (string, bool) {
+	if len(name) == 1 && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z') {
+		return name, true
+	}
+	if s, ok := glyphNames[name]; ok {
+		return s, true
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if n, err := strconv.ParseUint(name[3:], 16, 32); err == nil {
+			return string(rune(n)), true
+		}
+	}
+	return "", false
+}
+
+// pdfCMap is a parsed ToUnicode CMap.
+type pdfCMap struct {
+	codespaces []pdfCodespace
+	chars      map[string]string // source code bytes to text
+	ranges     []pdfCMapRange
+}
+
+type pdfCodespace struct {
+	low, high []byte
+}
+
+type pdfCMapRange struct {
+	low, high []byte
+	dst       []rune   // first destination, incremented across the range
+	dstArray  []string // explicit destinations, one per code
+}
+
+// parseCMap reads the codespace, bfchar and bfrange sections of a ToUnicode CMap.
+func parseCMap(data []byte) *pdfCMap {
+	cm := &pdfCMap{chars: map[string]string{}}
+	lex := &pdfLexer{data: data}
+	var operands []any
+
+	for {
+		obj, err := lex.readObject()
+		if err != nil {
+			break
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				low, ok1 := operands[i].([]byte)
+				high, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 && len(low) == len(high) && len(low) > 0 {
+					cm.codespaces = append(cm.codespaces, pdfCodespace{low: low, high: high})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					cm.chars[string(src)] = decodeUTF16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, ok1 := operands[i].([]byte)
+				high, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 || len(low) != len(high) {
+					continue
+				}
+				r := pdfCMapRange{low: low, high: high}
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					r.dst = []rune(decodeUTF16BE(dst))
+				case []any:
+					for _, d := range dst {
+						if b, ok := d.([]byte); ok {
+							r.dstArray = append(r.dstArray, decodeUTF16BE(b))
+						}
+					}
+				}
+				cm.ranges = append(cm.ranges, r)
+			}
+		}
+		if strings.HasPrefix(string(op), "end") || strings.HasPrefix(string(op), "begin") {
+			operands = operands[:0]
+		}
+	}
+	return cm
+}
+
+// decode converts a string of character codes to text. The length of each code is
+// taken from the codespace ranges, falling back to two bytes for composite fonts.
+func (cm *pdfCMap) decode(s []byte, composite bool) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		n := cm.codeLength(s, composite)
+		code := s[:n]
+		s = s[n:]
+		if text, ok := cm.lookup(code); ok {
+			b.WriteString(text)
+		}
+	}
+	return b.String()
+}
+
+func (cm *pdfCMap) codeLength(s []byte, composite bool) int {
+	for _, cs := range cm.codespaces {
+		n := len(cs.low)
+		if n <= len(s) && bytes.Compare(s[:n], cs.low) >= 0 && bytes.Compare(s[:n], cs.high) <= 0 {
+			return n
+		}
+	}
+	if composite && len(s) >= 2 {
+		return 2
+	}
+	return 1
+}
+
+func (cm *pdfCMap) lookup(code []byte) (string, bool) {
+	if text, ok := cm.chars[string(code)]; ok {
+		return text, true
+	}
+	for _, r := range cm.ranges {
+		if len(r.low) != len(code) || bytes.Compare(code, r.low) < 0 || bytes.Compare(code, r.high) > 0 {
+			continue
+		}
+		offset := codeValue(code) - codeValue(r.low)
+		if r.dstArray != nil {
+			if offset < len(r.dstArray) {
+				return r.dstArray[offset], true
+			}
+			return "", false
+		}
+		if len(r.dst) == 0 {
+			return "", false
+		}
+		dst := append([]rune(nil), r.dst...)
+		dst[len(dst)-1] += rune(offset)
+		return string(dst), true
+	}
+	return "", false
+}
+
+func codeValue(code []byte) int {
+	n := 0
+	for _, c := range code {
+		n = n<<8 | int(c)
+	}
+	return n
+}
+
+func decodeUTF16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	if len(b)%2 == 1 { // single byte destinations appear in some broken CMaps
+		units = append(units, uint16(b[len(b)-1]))
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,211 @@
+package ReadFunctions
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// minimalPDF is a valid single page PDF with no text.
+const minimalPDF = "%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n" +
+	"2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n" +
+	"3 0 obj\n<< /Type /Page /Parent 2 0 R >>\nendobj\n" +
+	"trailer\n<< /Root 1 0 R >>\n%%EOF\n"
+
+// pdfStreamObject returns a stream object body, Flate-compressed when compress is set.
+func pdfStreamObject(content string, compress bool) string {
+	if !compress {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+	}
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write([]byte(content))
+	_ = zw.Close()
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", buf.Len(), buf.String())
+}
+
+// buildPDF writes numbered objects (object 1 first) followed by a trailer. Any
+// updates are appended afterwards as an incremental update section.
+func buildPDF(objects []string, trailer string, updates map[int]string) []byte {
+	var b strings.Builder
+	b.WriteString("%PDF-1.7\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	fmt.Fprintf(&b, "xref\n0 1\n0000000000 65535 f \ntrailer\n%s\nstartxref\n0\n%%%%EOF\n", trailer)
+	for num, obj := range updates {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", num, obj)
+	}
+	if len(updates) > 0 {
+		fmt.Fprintf(&b, "trailer\n%s\n%%%%EOF\n", trailer)
+	}
+	return []byte(b.String())
+}
+
+func pdfLocations(segments []Segment) map[string][]string {
+	found := map[string][]string{}
+	for _, seg := range segments {
+		found[seg.Location] = append(found[seg.Location], seg.Text)
+	}
+	return found
+}
+
+func TestReadPDFFile(t *testing.T) {
+	cmap := `/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+1 beginbfchar <0020> <0020> endbfchar
+1 beginbfrange <0030> <0039> <0030> endbfrange
+3 beginbfchar <0041> <0053> <0042> <0053> <002D> <002D> endbfchar
+endcmap`
+
+	objects := []string{
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 6 0 R /F2 7 0 R >> >> >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents [9 0 R] >>`,
+		pdfStreamObject("BT /F1 12 Tf 72 720 Td (Patient: Mary Johnson) Tj 0 -14 Td [(Email: mary@) -10 (example.com)] TJ ET", true),
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+		`<< /Type /Font /Subtype /Type0 /Encoding /Identity-H /ToUnicode 8 0 R >>`,
+		pdfStreamObject(cmap, true),
+		pdfStreamObject("BT /F2 12 Tf 72 720 Td <00410042> Tj ( ) Tj ET", false),
+	}
+	update := map[int]string{
+		9: pdfStreamObject("BT /F2 12 Tf 72 720 Td <004100420020003100320033002D0034003500>Tj ET BT /F1 12 Tf 200 720 Td (-6789) Tj ET", true),
+	}
+
+	data := buildPDF(objects, "<< /Root 1 0 R /Size 10 >>", update)
+	segments, warnings, err := ReadPDFFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadPDFFile returned error: %v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	found := pdfLocations(segments)
+	want := map[string][]string{
+		"page 1": {"Patient: Mary Johnson", "Email: mary@example.com"},
+		"page 2": {"SS 123-45 -6789"},
+	}
+	for loc, lines := range want {
+		if strings.Join(found[loc], "|") != strings.Join(lines, "|") {
+			t.Errorf("%s text = %q; want %q", loc, found[loc], lines)
+		}
+	}
+}
+
+func TestReadPDFFileWarnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		warning string
+	}{
+		{
+			name: "encrypted",
+			data: buildPDF([]string{
+				`<< /Type /Catalog /Pages 2 0 R >>`,
+				`<< /Type /Pages /Kids [] /Count 0 >>`,
+				`<< /Filter /Standard /V 2 /R 3 >>`,
+			}, "<< /Root 1 0 R /Encrypt 3 0 R >>", nil),
+			warning: "encrypted",
+		},
+		{
+			name: "image only",
+			data: buildPDF([]string{
+				`<< /Type /Catalog /Pages 2 0 R >>`,
+				`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+				`<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /XObject << /Im1 5 0 R >> >> >>`,
+				pdfStreamObject("q 612 0 0 792 0 0 cm /Im1 Do Q", true),
+				`<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /Length 1 /Filter /DCTDecode >>
+stream
+x
+endstream`,
+			}, "<< /Root 1 0 R >>", nil),
+			warning: "image-only",
+		},
+	}
+
+	for _, tt := range tests {
+		segments, warnings, err := ReadPDFFile(bytes.NewReader(tt.data))
+		if err != nil {
+			t.Errorf("%s: ReadPDFFile returned error: %v", tt.name, err)
+			continue
+		}
+		if len(segments) != 0 {
+			t.Errorf("%s: got %d segments; want none", tt.name, len(segments))
+		}
+		if len(warnings) == 0 || !strings.Contains(warnings[0], tt.warning) {
+			t.Errorf("%s: warnings = %v; want one mentioning %q", tt.name, warnings, tt.warning)
+		}
+	}
+}
+
+func TestReadPDFFileMalformed(t *testing.T) {
+	objStm := func(dict, content string) string {
+		return fmt.Sprintf("<< /Type /ObjStm %s /Length %d >>\nstream\n%s\nendstream", dict, len(content), content)
+	}
+	pages := []string{
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources 5 0 R >>`,
+		pdfStreamObject("BT /F1 12 Tf 72 720 Td (Page text) Tj ET /Fm1 Do", false),
+		`<< /Font << /F1 6 0 R >> /XObject << /Fm1 7 0 R >> >>`,
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+		`<< /Type /XObject /Subtype /Form /Resources 5 0 R /Length 36 >>
+stream
+BT 72 700 Td (Form text) Tj ET /Fm1 Do
+endstream`,
+	}
+
+	tests := []struct {
+		name  string
+		extra string
+		want  []string
+	}{
+		{
+			name:  "negative /First",
+			extra: objStm("/N 1 /First -3", "9 0 (x)"),
+			want:  []string{"Page text", "Form text"},
+		},
+		{
+			name:  "negative object offset",
+			extra: objStm("/N 1 /First 6", "9 -100 (x)"),
+			want:  []string{"Page text", "Form text"},
+		},
+		{
+			name:  "huge /Length",
+			extra: "<< /Length 100000000000000000000000000000 >>\nstream\nabc\nendstream",
+			want:  []string{"Page text", "Form text"},
+		},
+		{
+			name:  "huge /First",
+			extra: objStm("/N 1 /First 100000000000000000000000000000", "9 0 (x)"),
+			want:  []string{"Page text", "Form text"},
+		},
+		{
+			name:  "huge object offset",
+			extra: objStm("/N 1 /First 6", "9 100000000000000000000000000000 (x)"),
+			want:  []string{"Page text", "Form text"},
+		},
+		{
+			name:  "huge /N",
+			extra: objStm("/N 100000000000000000000000000000 /First 6", "9 0 (x)"),
+			want:  []string{"Page text", "Form text"},
+		},
+	}
+
+	for _, tt := range tests {
+		data := buildPDF(append(pages[:len(pages):len(pages)], tt.extra), "<< /Root 1 0 R >>", nil)
+		segments, _, err := ReadPDFFile(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: ReadPDFFile returned error: %v", tt.name, err)
+			continue
+		}
+		if got := pdfLocations(segments)["page 1"]; strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: page 1 text = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,671 @@
+package ReadFunctions
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// SQLResult is what ReadSQLFile found in a SQL script or database dump.
+type SQLResult struct {
+	Detections []PIIDetection
+	Columns    []ColumnSummary // table columns with at least one detection, in the order first seen
+	Warnings   []string
+	Preview    string
+}
+
+type sqlTokenKind int
+
+const (
+	sqlEOF    sqlTokenKind = iota
+	sqlWord                // keyword or bare identifier
+	sqlIdent               // quoted identifier
+	sqlString              // string literal, unescaped
+	sqlNumber              // numeric literal
+	sqlPunct               // any other single character
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+	line int
+}
+
+// is reports whether t is the punctuation character c.
+func (t sqlToken) is(c string) bool { return t.kind == sqlPunct && t.text == c }
+
+// keyword returns t upper-cased when it is a bare word, or "" otherwise.
+func (t sqlToken) keyword() string {
+	if t.kind != sqlWord {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
+
+// sqlLexer splits SQL into tokens as it is read, skipping comments, so statements
+// of any size can be parsed without holding them in memory.
+type sqlLexer struct {
+	r            *bufio.Reader
+	line         int
+	err          error // first read error other than io.EOF
+	unterminated bool  // a string, identifier or comment ran to the end of the input
+	// backslashEscapes is MySQL's treatment of '\' in string literals. It is turned
+	// off for pg_dump output, where only E'' strings use escapes.
+	backslashEscapes bool
+}
+
+func newSQLLexer(r io.Reader) *sqlLexer {
+	return &sqlLexer{r: bufio.NewReaderSize(r, 64*1024), line: 1, backslashEscapes: true}
+}
+
+func (l *sqlLexer) readRune() (rune, bool) {
+	r, _, err := l.r.ReadRune()
+	if err != nil {
+		if !errors.Is(err, io.EOF) && l.err == nil {
+			l.err = err
+		}
+		return 0, false
+	}
+	if r == '\n' {
+		l.line++
+	}
+	return r, true
+}
+
+func (l *sqlLexer) unreadRune(r rune) {
+	_ = l.r.UnreadRune()
+	if r == '\n' {
+		l.line--
+	}
+}
+
+// peekByte returns the next byte without consuming it, or 0 at the end of input.
+func (l *sqlLexer) peekByte() byte {
+	b, err := l.r.Peek(1)
+	if err != nil {
+		return 0
+	}
+	return b[0]
+}
+
+// next returns the next token, or a token of kind sqlEOF at the end of input.
+func (l *sqlLexer) next() sqlToken {
+	for {
+		r, ok := l.readRune()
+		if !ok {
+			return sqlToken{kind: sqlEOF, line: l.line}
+		}
+		line := l.line
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r == '-' && l.peekByte() == '-', r == '#':
+			l.skipLineComment()
+			continue
+		case r == '/' && l.peekByte() == '*':
+			l.skipBlockComment()
+			continue
+		case r == '\'':
+			return sqlToken{kind: sqlString, text: l.readQuoted('\'', l.backslashEscapes), line: line}
+		case r == '"' || r == '`':
+			return sqlToken{kind: sqlIdent, text: l.readQuoted(r, false), line: line}
+		case r == '$':
+			if text, ok := l.readDollarQuoted(); ok {
+				return sqlToken{kind: sqlString, text: text, line: line}
+			}
+			return sqlToken{kind: sqlPunct, text: "$", line: line}
+		case unicode.IsLetter(r) || r == '_':
+			word := l.readWhile(r, func(r rune) bool {
+				return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+			})
+			// String prefixes: E'' escapes, N'' national, X'' and B'' binary, and
+			// MySQL charset introducers such as _utf8mb4''.
+			if l.peekByte() == '\'' && (len(word) == 1 && strings.ContainsAny(word, "EeNnXxBb") || word[0] == '_') {
+				l.readRune()
+				escapes := l.backslashEscapes || word == "E" || word == "e"
+				return sqlToken{kind: sqlString, text: l.readQuoted('\'', escapes), line: line}
+			}
+			return sqlToken{kind: sqlWord, text: word, line: line}
+		case unicode.IsDigit(r) || r == '.' && l.peekByte() >= '0' && l.peekByte() <= '9':
+			prev := rune(0)
+			number := l.readWhile(r, func(r rune) bool {
+				ok := unicode.IsDigit(r) || r == '.' || r == 'e' || r == 'E' ||
+					(r == '+' || r == '-') && (prev == 'e' || prev == 'E')
+				prev = r
+				return ok
+			})
+			return sqlToken{kind: sqlNumber, text: number, line: line}
+		default:
+			return sqlToken{kind: sqlPunct, text: string(r), line: line}
+		}
+	}
+}
+
+// readWhile returns first followed by the runes after it that satisfy ok.
+func (l *sqlLexer) readWhile(first rune, ok func(rune) bool) string {
+	var b strings.Builder
+	b.WriteRune(first)
+	for {
+		r, more := l.readRune()
+		if !more {
+			return b.String()
+		}
+		if !ok(r) {
+			l.unreadRune(r)
+			return b.String()
+		}
+		b.WriteRune(r)
+	}
+}
+
+// readQuoted reads up to the closing quote, which may be doubled to include it.
+func (l *sqlLexer) readQuoted(quote rune, escapes bool) string {
+	var b strings.Builder
+	for {
+		r, ok := l.readRune()
+		if !ok {
+			l.unterminated = true
+			return b.String()
+		}
+		switch {
+		case r == '\\' && escapes:
+			e, ok := l.readRune()
+			if !ok {
+				l.unterminated = true
+				return b.String()
+			}
+			b.WriteRune(sqlEscape(e))
+		case r == quote:
+			if l.peekByte() != byte(quote) {
+				return b.String()
+			}
+			l.readRune()
+			b.WriteRune(quote)
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// readDollarQuoted reads a PostgreSQL $tag$...$tag$ string once the opening '$'
+// has been consumed. It reports false, consuming nothing more, for a '$' that
+// doesn't open one, such as a positional parameter.
+func (l *sqlLexer) readDollarQuoted() (string, bool) {
+	peek, _ := l.r.Peek(64)
+	end := -1
+	for i, c := range peek {
+		if c == '$' {
+			end = i
+			break
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			break
+		}
+	}
+	if end < 0 {
+		return "", false
+	}
+	delim := "$" + string(peek[:end+1])
+	for i := 0; i <= end; i++ {
+		l.readRune()
+	}
+
+	var b strings.Builder
+	for {
+		r, ok := l.readRune()
+		if !ok {
+			l.unterminated = true
+			return b.String(), true
+		}
+		b.WriteRune(r)
+		if r == '$' && strings.HasSuffix(b.String(), delim) {
+			return strings.TrimSuffix(b.String(), delim), true
+		}
+	}
+}
+
+func (l *sqlLexer) skipLineComment() {
+	var comment strings.Builder
+	for {
+		r, ok := l.readRune()
+		if !ok || r == '\n' {
+			break
+		}
+		if comment.Len() < 128 {
+			comment.WriteRune(r)
+		}
+	}
+	if strings.Contains(comment.String(), "PostgreSQL database dump") {
+		l.backslashEscapes = false
+	}
+}
+
+func (l *sqlLexer) skipBlockComment() {
+	l.readRune() // the '*' after '/'
+	prev := rune(0)
+	for {
+		r, ok := l.readRune()
+		if !ok {
+			l.unterminated = true
+			return
+		}
+		if prev == '*' && r == '/' {
+			return
+		}
+		prev = r
+	}
+}
+
+// readLine returns the rest of the current line without its line ending, and false
+// once the input is exhausted.
+func (l *sqlLexer) readLine() (string, bool) {
+	line, err := l.r.ReadString('\n')
+	if err != nil {
+		if !errors.Is(err, io.EOF) && l.err == nil {
+			l.err = err
+		}
+		if line == "" {
+			return "", false
+		}
+	} else {
+		l.line++
+	}
+	return strings.TrimRight(line, "\r\n"), true
+}
+
+// sqlEscape maps the character after a backslash in a MySQL or E” string.
+func sqlEscape(r rune) rune {
+	switch r {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	case 'b':
+		return '\b'
+	case 'Z':
+		return 0x1A
+	default:
+		return r
+	}
+}
+
+// sqlTableColumn identifies a column of a table by name, or by position ("c3")
+// when the dump doesn't name it.
+type sqlTableColumn struct {
+	table  string
+	column string
+}
+
+// sqlParser walks the statements of a dump, attributing literal values to the
+// table and column they are stored in.
+type sqlParser struct {
+	lex     *sqlLexer
+	tables  map[string][]string // lower-cased table name -> column names from CREATE TABLE
+	rows    map[string]int      // rows seen per table
+	tallies map[sqlTableColumn]*columnTally
+	indexes map[sqlTableColumn]int // position of each column when first seen
+	order   []sqlTableColumn       // tallies in the order first seen
+	result  SQLResult
+	preview previewBuilder
+}
+
+// sqlConstraintWords start table elements in CREATE TABLE that are not columns.
+var sqlConstraintWords = map[string]bool{
+	"PRIMARY": true, "KEY": true, "UNIQUE": true, "INDEX": true, "CONSTRAINT": true,
+	"FOREIGN": true, "CHECK": true, "FULLTEXT": true, "SPATIAL": true, "EXCLUDE": true,
+	"LIKE": true, "PERIOD": true,
+}
+
+// ReadSQLFile scans a SQL script or database dump such as mysqldump or pg_dump
+// output. Column names are taken from CREATE TABLE statements and INSERT column
+// lists, so each value in an INSERT ... VALUES tuple or a COPY ... FROM stdin block
+// is scanned knowing its table and column: detections are located as
+// "users.ssn row 12" and the Scanner gets "users.ssn" as Segment.Field. String
+// literals in other statements are still scanned, without a location.
+//
+// Statements are tokenised as they are read, so extended INSERTs of any length
+// are handled without holding them in memory.
+func ReadSQLFile(OpenFile io.Reader) (SQLResult, error) {
+	p := &sqlParser{
+		lex:     newSQLLexer(OpenFile),
+		tables:  map[string][]string{},
+		rows:    map[string]int{},
+		tallies: map[sqlTableColumn]*columnTally{},
+		indexes: map[sqlTableColumn]int{},
+	}
+
+	err := p.parse()
+	if err == nil {
+		err = p.lex.err
+	}
+	if err != nil {
+		return p.result, err
+	}
+	if p.lex.unterminated {
+		p.result.Warnings = append(p.result.Warnings, "sql input ended inside a string, identifier or comment")
+	}
+
+	for _, key := range p.order {
+		if summary, ok := p.tallies[key].summary(p.indexes[key]); ok {
+			p.result.Columns = append(p.result.Columns, summary)
+		}
+	}
+	p.result.Preview = contentPreview(p.preview.String())
+	return p.result, nil
+}
+
+func (p *sqlParser) parse() error {
+	for {
+		tok := p.lex.next()
+		var err error
+		switch tok.keyword() {
+		case "":
+			if tok.kind == sqlEOF {
+				return nil
+			}
+			err = p.skipStatement(tok)
+		case "CREATE":
+			err = p.parseCreate()
+		case "INSERT", "REPLACE":
+			err = p.parseInsert()
+		case "COPY":
+			err = p.parseCopy()
+		default:
+			err = p.skipStatement(tok)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// skipStatement consumes tokens up to the end of the statement, scanning any string
+// literals on the way. A literal assigned to or compared with a column, as in
+// "SET ssn = '...'", gets the column name as its field.
+func (p *sqlParser) skipStatement(tok sqlToken) error {
+	var prev, prevPrev sqlToken
+	for ; tok.kind != sqlEOF && !tok.is(";"); tok = p.lex.next() {
+		if tok.kind == sqlString {
+			field := ""
+			if prev.is("=") && (prevPrev.kind == sqlWord || prevPrev.kind == sqlIdent) {
+				field = prevPrev.text
+			}
+			if err := p.scanValue(tok, "", -1, 0, field); err != nil {
+				return err
+			}
+		}
+		prevPrev, prev = prev, tok
+	}
+	return nil
+}
+
+// readName reads a possibly schema-qualified name starting with tok.
+func (p *sqlParser) readName(tok sqlToken) (string, sqlToken) {
+	name := tok.text
+	for {
+		next := p.lex.next()
+		if !next.is(".") {
+			return name, next
+		}
+		part := p.lex.next()
+		name += "." + part.text
+	}
+}
+
+// readColumnList reads a parenthesised list of names once the '(' has been read.
+func (p *sqlParser) readColumnList() []string {
+	var names []string
+	for tok := p.lex.next(); tok.kind != sqlEOF && !tok.is(")"); tok = p.lex.next() {
+		if tok.kind == sqlWord || tok.kind == sqlIdent {
+			names = append(names, tok.text)
+		}
+	}
+	return names
+}
+
+func (p *sqlParser) parseCreate() error {
+	tok := p.lex.next()
+	for tok.kind == sqlWord && tok.keyword() != "TABLE" {
+		tok = p.lex.next()
+	}
+	if tok.keyword() != "TABLE" {
+		return p.skipStatement(tok)
+	}
+
+	tok = p.lex.next()
+	for tok.keyword() == "IF" || tok.keyword() == "NOT" || tok.keyword() == "EXISTS" {
+		tok = p.lex.next()
+	}
+	if tok.kind != sqlWord && tok.kind != sqlIdent {
+		return p.skipStatement(tok)
+	}
+	table, tok := p.readName(tok)
+	if !tok.is("(") {
+		return p.skipStatement(tok)
+	}
+
+	var columns []string
+	depth, itemStart := 1, true
+	for depth > 0 {
+		tok = p.lex.next()
+		switch {
+		case tok.kind == sqlEOF:
+			depth = 0
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+		case tok.is(",") && depth == 1:
+			itemStart = true
+		case itemStart && depth == 1:
+			itemStart = false
+			if (tok.kind == sqlWord || tok.kind == sqlIdent) && !(tok.kind == sqlWord && sqlConstraintWords[tok.keyword()]) {
+				columns = append(columns, tok.text)
+			}
+		}
+	}
+	p.tables[strings.ToLower(table)] = columns
+	return p.skipStatement(p.lex.next())
+}
+
+func (p *sqlParser) parseInsert() error {
+	tok := p.lex.next()
+	for {
+		switch tok.keyword() {
+		case "INTO", "IGNORE", "LOW_PRIORITY", "DELAYED", "HIGH_PRIORITY":
+			tok = p.lex.next()
+			continue
+		}
+		break
+	}
+	if tok.kind != sqlWord && tok.kind != sqlIdent {
+		return p.skipStatement(tok)
+	}
+	table, tok := p.readName(tok)
+
+	columns := p.tables[strings.ToLower(table)]
+	if tok.is("(") {
+		columns = p.readColumnList()
+		tok = p.lex.next()
+	}
+	if tok.keyword() != "VALUES" && tok.keyword() != "VALUE" {
+		return p.skipStatement(tok)
+	}
+
+	for {
+		tok = p.lex.next()
+		if tok.keyword() == "ROW" {
+			tok = p.lex.next()
+		}
+		if !tok.is("(") {
+			return p.skipStatement(tok)
+		}
+		if err := p.readTuple(table, columns); err != nil {
+			return err
+		}
+
+		tok = p.lex.next()
+		if !tok.is(",") {
+			return p.skipStatement(tok)
+		}
+	}
+}
+
+// readTuple scans the values of one VALUES tuple once its '(' has been read.
+func (p *sqlParser) readTuple(table string, columns []string) error {
+	p.rows[strings.ToLower(table)]++
+	row := p.rows[strings.ToLower(table)]
+
+	index, depth := 0, 1
+	for depth > 0 {
+		tok := p.lex.next()
+		switch {
+		case tok.kind == sqlEOF:
+			return nil
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+		case tok.is(",") && depth == 1:
+			index++
+		case tok.kind == sqlString || tok.kind == sqlNumber:
+			if err := p.scanValue(tok, table, index, row, columnName(columns, index)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *sqlParser) parseCopy() error {
+	tok := p.lex.next()
+	if tok.kind != sqlWord && tok.kind != sqlIdent {
+		return p.skipStatement(tok)
+	}
+	table, tok := p.readName(tok)
+
+	columns := p.tables[strings.ToLower(table)]
+	if tok.is("(") {
+		columns = p.readColumnList()
+		tok = p.lex.next()
+	}
+
+	fromStdin := false
+	for prev := ""; tok.kind != sqlEOF && !tok.is(";"); tok = p.lex.next() {
+		if prev == "FROM" && tok.keyword() == "STDIN" {
+			fromStdin = true
+		}
+		prev = tok.keyword()
+	}
+	if !fromStdin || tok.kind == sqlEOF {
+		return nil
+	}
+
+	p.lex.readLine() // rest of the COPY statement's line
+	for {
+		line, ok := p.lex.readLine()
+		if !ok || line == `\.` {
+			return nil
+		}
+		p.rows[strings.ToLower(table)]++
+		row := p.rows[strings.ToLower(table)]
+
+		for index, field := range strings.Split(line, "\t") {
+			if field == `\N` || field == "" {
+				continue
+			}
+			tok := sqlToken{kind: sqlString, text: unescapeCopyField(field), line: p.lex.line - 1}
+			if err := p.scanValue(tok, table, index, row, columnName(columns, index)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// unescapeCopyField decodes the backslash escapes of PostgreSQL's COPY text format.
+func unescapeCopyField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] != '\\' || i+1 == len(field) {
+			b.WriteByte(field[i])
+			continue
+		}
+		i++
+		switch field[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case 'b':
+			b.WriteByte('\b')
+		default:
+			b.WriteByte(field[i])
+		}
+	}
+	return b.String()
+}
+
+// columnName returns the name of the column at index, or "" when it isn't known.
+func columnName(columns []string, index int) string {
+	if index < len(columns) {
+		return columns[index]
+	}
+	return ""
+}
+
+// scanValue scans a literal found in table's column at index, or outside any table
+// when table is empty, and tallies what was found against the column.
+func (p *sqlParser) scanValue(tok sqlToken, table string, index, row int, column string) error {
+	if strings.TrimSpace(tok.text) == "" {
+		return nil
+	}
+	if tok.kind == sqlString {
+		p.preview.add(tok.text)
+	}
+
+	seg := Segment{Text: tok.text, LineNumber: tok.line, Field: column}
+	name := column
+	if table != "" {
+		if name == "" {
+			name = fmt.Sprintf("c%d", index+1)
+		} else {
+			seg.Field = table + "." + column
+		}
+		seg.Location = fmt.Sprintf("%s.%s row %d", table, name, row)
+	}
+
+	detections, err := scanSegment(seg)
+	if err != nil {
+		return err
+	}
+	p.result.Detections = append(p.result.Detections, detections...)
+
+	if table != "" {
+		key := sqlTableColumn{table: strings.ToLower(table), column: strings.ToLower(name)}
+		tally, ok := p.tallies[key]
+		if !ok {
+			tally = &columnTally{table: table, header: column, types: map[string]int{}}
+			p.tallies[key] = tally
+			p.indexes[key] = index
+			p.order = append(p.order, key)
+		}
+		tally.add(detections)
+	}
+	return nil
+}
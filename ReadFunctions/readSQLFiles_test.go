@@ -0,0 +1,98 @@
+package ReadFunctions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadSQLFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		fields   map[string]string // location -> field handed to the scanner
+		lines    []int
+		column   string
+		warnings int
+	}{
+		{
+			name: "mysqldump",
+			input: "-- MySQL dump 10.13\n/*!40101 SET NAMES utf8 */;\n" +
+				"CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `note` text,\n  `ssn` char(11),\n  PRIMARY KEY (`id`)\n);\n" +
+				"INSERT INTO `users` VALUES (1,'it\\'s; fine','123-45-6789'),(2,NULL,_utf8mb4'987-65-4321');\n" +
+				"INSERT INTO users (ssn, id) VALUES ('111-22-3333', 3);\n",
+			fields: map[string]string{
+				"users.ssn row 1": "users.ssn",
+				"users.ssn row 2": "users.ssn",
+				"users.ssn row 3": "users.ssn",
+			},
+			lines:  []int{9, 9, 10},
+			column: "column users.ssn is 100% ssn-shaped",
+		},
+		{
+			name: "pg_dump",
+			input: "--\n-- PostgreSQL database dump\n--\n" +
+				"CREATE FUNCTION f() RETURNS text AS $body$ SELECT 'x;y' $body$ LANGUAGE sql;\n" +
+				"CREATE TABLE public.patients (id integer, tax_id text);\n" +
+				"COPY public.patients (id, tax_id) FROM stdin;\n1\t123-45-6789\n2\t\\N\n3\tC:\\\\path\n\\.\n" +
+				"UPDATE public.patients SET tax_id = E'111-22-3333\\n' WHERE id = 4;\n",
+			fields: map[string]string{
+				"public.patients.tax_id row 1": "public.patients.tax_id",
+				"":                             "tax_id",
+			},
+			lines:  []int{7, 11},
+			column: "column public.patients.tax_id is 50% ssn-shaped",
+		},
+		{
+			name:     "no column names",
+			input:    "INSERT INTO t VALUES ('a', '123-45-6789');\nINSERT INTO t VALUES ('b', 'unterminated",
+			fields:   map[string]string{"t.c2 row 1": ""},
+			lines:    []int{1},
+			column:   "column t.c2 is 50% ssn-shaped",
+			warnings: 1,
+		},
+	}
+
+	defer SetScanner(nil)
+	for _, tt := range tests {
+		fields := map[string]string{}
+		SetScanner(digitScanner(fields))
+
+		result, err := ReadSQLFile(strings.NewReader(tt.input))
+		if err != nil {
+			t.Errorf("%s: ReadSQLFile returned error: %v", tt.name, err)
+			continue
+		}
+		if len(result.Warnings) != tt.warnings {
+			t.Errorf("%s: warnings = %v; want %d", tt.name, result.Warnings, tt.warnings)
+		}
+
+		var lines []int
+		for _, d := range result.Detections {
+			lines = append(lines, d.LineNumber)
+		}
+		if len(lines) != len(tt.lines) {
+			t.Errorf("%s: detections on lines %v; want %v", tt.name, lines, tt.lines)
+		} else {
+			for i := range lines {
+				if lines[i] != tt.lines[i] {
+					t.Errorf("%s: detections on lines %v; want %v", tt.name, lines, tt.lines)
+					break
+				}
+			}
+		}
+
+		for loc, field := range tt.fields {
+			if got, ok := fields[loc]; !ok || got != field {
+				t.Errorf("%s: location %q field = %q (found %v); want %q", tt.name, loc, got, ok, field)
+			}
+		}
+
+		var columns []string
+		for _, c := range result.Columns {
+			columns = append(columns, c.Description)
+		}
+		if len(columns) != 1 || columns[0] != tt.column {
+			t.Errorf("%s: column summaries = %q; want [%q]", tt.name, columns, tt.column)
+		}
+	}
+}
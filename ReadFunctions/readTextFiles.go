@@ -1,25 +1,83 @@
 package ReadFunctions
 
 import (
-	"io"
+	"context"
+	"strings"
 )
 
-func ReadJSONFile(OpenFile io.Reader) ([]string, error) {
-	// Implementation for reading a JSON file
-	return nil, nil // Placeholder return
-}
-func ReadCSVFile(OpenFile io.Reader) ([]string, error) {
-	// Implementation for reading a CSV file
-	return nil, nil // Placeholder return
+// ReadTextFile runs the registered Scanner over each line, tracking line numbers and
+// byte offsets so detections can be located in the original file.
+// The lines are assumed to have been split on "\n".
+func ReadTextFile(lines []string) ([]PIIDetection, error) {
+	offsets := make([]int, len(lines))
+	offset := 0
+	for i, line := range lines {
+		offsets[i] = offset
+		offset += len(line) + 1
+	}
+	return readTextLines(context.Background(), lines, offsets)
 }
 
-func ReadTextFile(lines []string) ([]PIIDetection, error) {
+// readTextLines is ReadTextFile with cancellation checked between lines. offsets
+// holds the byte offset in the file of each line, as returned by the line readers,
+// so stripped "\r\n" line endings are accounted for.
+func readTextLines(ctx context.Context, lines []string, offsets []int) ([]PIIDetection, error) {
 	var fileDetections []PIIDetection
 
+	previous := 0 // index in fileDetections of the first detection on the previous line
+	for i, line := range lines {
+		if err := ctx.Err(); err != nil {
+			return fileDetections, err
+		}
+		detections, err := scanSegment(Segment{Text: line, LineNumber: i + 1, Offset: offsets[i]})
+		if err != nil {
+			return fileDetections, err
+		}
+		if i > 0 {
+			detections = joinAddressLines(fileDetections[previous:], detections, lines[i-1], line, offsets[i-1], offsets[i])
+		}
+		previous = len(fileDetections)
+		fileDetections = append(fileDetections, detections...)
+	}
+
 	return fileDetections, nil
 }
 
-func ReadSQLFile(OpenFile io.Reader) ([]string, error) {
-	// Implementation for reading a SQL file
-	return nil, nil // Placeholder return
+// joinAddressLines merges a street or PO box address that ends the previous line
+// with a city, state and ZIP address that starts this one, the way postal addresses
+// are usually written. The street detection in previous is replaced by the whole
+// address and the city detection is dropped from current, which is returned.
+// prevOffset and offset are the byte offsets of prevLine and line in the file, so
+// the joined Value is exactly the file's bytes between its offsets.
+func joinAddressLines(previous, current []PIIDetection, prevLine, line string, prevOffset, offset int) []PIIDetection {
+	street := -1
+	for i, d := range previous {
+		if d.Type == "address" && (d.Subtype == "street" || d.Subtype == "po_box") &&
+			strings.Trim(prevLine[d.EndOffset-prevOffset:], " \t,") == "" {
+			street = i
+		}
+	}
+	city := -1
+	for i, d := range current {
+		if d.Type == "address" && d.Subtype == "city_state_zip" &&
+			strings.TrimSpace(line[:d.StartOffset-offset]) == "" {
+			city = i
+			break
+		}
+	}
+	if street < 0 || city < 0 {
+		return current
+	}
+
+	s, c := &previous[street], current[city]
+	lineEnd := "\r\n"[2-(offset-prevOffset-len(prevLine)):] // "\n" or "\r\n", as in the file
+	between := prevLine[s.EndOffset-prevOffset:] + lineEnd + line[:c.StartOffset-offset]
+	s.Value += between + c.Value
+	s.RedactedValue += between + c.RedactedValue
+	s.Context += "\n" + c.Context
+	s.EndOffset = c.EndOffset
+	s.Subtype = ""
+	s.Confidence = 1 - (1-s.Confidence)*(1-c.Confidence) // two independent parts of one address
+
+	return append(current[:city:city], current[city+1:]...)
 }
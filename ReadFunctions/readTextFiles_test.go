@@ -0,0 +1,130 @@
+package ReadFunctions
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// addressScanner reports "<number> Main St" as a street line and "Springfield, IL
+// <zip>" as a city line, standing in for RegexProcessing.ScanSegment.
+func addressScanner() Scanner {
+	parts := map[string]*regexp.Regexp{
+		"street":         regexp.MustCompile(`\d+ Main St`),
+		"city_state_zip": regexp.MustCompile(`Springfield, IL \d{5}`),
+	}
+	return func(seg Segment) ([]PIIDetection, error) {
+		var detections []PIIDetection
+		for _, subtype := range []string{"street", "city_state_zip"} {
+			for _, loc := range parts[subtype].FindAllStringIndex(seg.Text, -1) {
+				detections = append(detections, PIIDetection{
+					Type:          "address",
+					Subtype:       subtype,
+					Value:         seg.Text[loc[0]:loc[1]],
+					RedactedValue: "X",
+					StartOffset:   seg.Offset + loc[0],
+					EndOffset:     seg.Offset + loc[1],
+					LineNumber:    seg.LineNumber,
+					Confidence:    0.6,
+				})
+			}
+		}
+		return detections, nil
+	}
+}
+
+func TestReadTextFileJoinsAddressLines(t *testing.T) {
+	SetScanner(addressScanner())
+	defer SetScanner(nil)
+
+	tests := []struct {
+		name   string
+		lines  []string
+		values []string
+	}{
+		{"street then city", []string{"Jane Doe", "123 Main St,", "  Springfield, IL 62704", "thanks"},
+			[]string{"123 Main St,\n  Springfield, IL 62704"}},
+		{"text after the street", []string{"123 Main St is closed", "Springfield, IL 62704"},
+			[]string{"123 Main St", "Springfield, IL 62704"}},
+		{"blank line between", []string{"123 Main St", "", "Springfield, IL 62704"},
+			[]string{"123 Main St", "Springfield, IL 62704"}},
+	}
+
+	for _, tt := range tests {
+		detections, err := ReadTextFile(tt.lines)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(detections) != len(tt.values) {
+			t.Errorf("%s: got %d detections, want %d", tt.name, len(detections), len(tt.values))
+			continue
+		}
+		for i, d := range detections {
+			if d.Value != tt.values[i] {
+				t.Errorf("%s: detection %d = %q, want %q", tt.name, i, d.Value, tt.values[i])
+			}
+		}
+	}
+
+	detections, _ := ReadTextFile([]string{"x", "9 Main St", "Springfield, IL 62704"})
+	d := detections[0]
+	if d.Subtype != "" || d.LineNumber != 2 || d.StartOffset != 2 || d.EndOffset != 33 || d.Confidence != 1-0.4*0.4 {
+		t.Errorf("joined address = %+v; want whole address from line 2, offsets [2:33]", d)
+	}
+
+	lines, offsets, _ := readInMemory(strings.NewReader("123 Main St,\r\nSpringfield, IL 62704\r\n"))
+	detections, _ = readTextLines(context.Background(), lines, offsets)
+	if len(detections) != 1 || detections[0].Value != "123 Main St,\r\nSpringfield, IL 62704" ||
+		detections[0].StartOffset != 0 || detections[0].EndOffset != 35 {
+		t.Errorf("CRLF address = %+v; want one address over offsets [0:35]", detections)
+	}
+}
+
+func TestReadLinesCRLF(t *testing.T) {
+	digits := regexp.MustCompile(`\d+`)
+	SetScanner(func(seg Segment) ([]PIIDetection, error) {
+		var detections []PIIDetection
+		for _, loc := range digits.FindAllStringIndex(seg.Text, -1) {
+			detections = append(detections, PIIDetection{
+				Value:       seg.Text[loc[0]:loc[1]],
+				StartOffset: seg.Offset + loc[0],
+				EndOffset:   seg.Offset + loc[1],
+			})
+		}
+		return detections, nil
+	})
+	defer SetScanner(nil)
+
+	const data = "a 1\r\nbb 22\r\n\r\nccc 333\nd 4"
+	readers := []struct {
+		name string
+		read func(io.Reader) ([]string, []int, error)
+	}{
+		{"readInMemory", readInMemory},
+		{"readLargeFile", readLargeFile},
+	}
+
+	for _, r := range readers {
+		lines, offsets, err := r.read(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", r.name, err)
+		}
+		if got := strings.Join(lines, "|"); got != "a 1|bb 22||ccc 333|d 4" {
+			t.Errorf("%s: lines = %q, want line endings stripped", r.name, got)
+		}
+		detections, err := readTextLines(context.Background(), lines, offsets)
+		if err != nil {
+			t.Fatalf("%s: %v", r.name, err)
+		}
+		if len(detections) != 4 {
+			t.Fatalf("%s: got %d detections, want 4", r.name, len(detections))
+		}
+		for _, d := range detections {
+			if got := data[d.StartOffset:d.EndOffset]; got != d.Value {
+				t.Errorf("%s: offsets [%d:%d] hold %q, want %q", r.name, d.StartOffset, d.EndOffset, got, d.Value)
+			}
+		}
+	}
+}
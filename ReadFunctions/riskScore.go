@@ -0,0 +1,142 @@
+package ReadFunctions
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// RiskModel describes how RiskScore and ConfidenceScore are derived. It is written
+// into every report so the scores can be audited and compared across versions.
+const RiskModel = "Each detection scores w*c, its type's sensitivity weight w (ssn 1.0 ... email 0.35; " +
+	"secrets by severity) times its confidence c. S is the highest such score. V = W/(W+10), where W " +
+	"is the sum of the scores, grows with volume. C = 1-0.5^(k-1), where k is the most distinct direct " +
+	"identifier types found in one record (a row of a CSV file, SQL table, spreadsheet or document " +
+	"table, a JSON object, or a line of text), measures identifiers for the same person found " +
+	"together, such as name, date of birth and SSN. P is the summed confidence of clinical codes, capped " +
+	"at 1, when the file also has a direct identifier, and 0 otherwise. " +
+	"risk_score = 1 - (1-S)(1-0.5V)(1-0.6C)(1-0.8P). " +
+	"confidence_score is the mean confidence of the detections, weighted by w."
+
+// sensitivityWeights is how much harm exposing each type of identifier can do, from
+// 1 for identifiers that enable identity theft on their own down to 0.1 for
+// identifiers that are public. Types not listed weigh defaultSensitivity.
+var sensitivityWeights = map[string]float64{
+	"ssn": 1, "national_id": 1, "credit_card": 0.9, "mbi": 0.9, "bank_account": 0.85, "iban": 0.85,
+	"health_plan": 0.8, "mrn": 0.8, "dob": 0.6, "address": 0.55, "dea": 0.5,
+	"phone": 0.4, "name": 0.4, "imei": 0.4, "vin": 0.4, "email": 0.35,
+	"ein": 0.3, "aba_routing": 0.3, "ip_address": 0.3, "mac_address": 0.3, "url": 0.3,
+	"swift_bic": 0.2, "icd10": 0.2, "cpt": 0.2, "ndc": 0.2, "loinc": 0.2, "npi": 0.1,
+}
+
+// secretSensitivity weighs secrets by Severity rather than by type.
+var secretSensitivity = map[string]float64{"critical": 1, "high": 0.85, "medium": 0.6, "low": 0.4}
+
+const (
+	defaultSensitivity = 0.3
+	// volumeHalfPoint is the summed detection score at which V reaches 0.5.
+	volumeHalfPoint = 10
+
+	volumeWeight       = 0.5
+	coOccurrenceWeight = 0.6
+	phiContextWeight   = 0.8
+)
+
+var (
+	// csvCellRegex, sqlCellRegex, sheetCellRegex and tableCellRegex match the Locations
+	// the readers give cells, capturing the parts that identify the row.
+	csvCellRegex   = regexp.MustCompile(`^(row \d+) c\d+`)
+	sqlCellRegex   = regexp.MustCompile(`^(.+)\.[^.]+ (row \d+)$`)
+	sheetCellRegex = regexp.MustCompile(`^(.+)!\$?[A-Z]+\$?(\d+)`)
+	tableCellRegex = regexp.MustCompile(`^(.+ table \d+ row \d+) cell \d+$`)
+	// jsonMemberRegex matches the last member or index of a JSON path.
+	jsonMemberRegex = regexp.MustCompile(`(?:\['(?:[^'\\]|\\.)*'\]|\[\d+\]|\.[^.\[]+)$`)
+)
+
+// RiskFactors are the inputs to a file's RiskScore, as described by RiskModel, and
+// the formula with them filled in.
+type RiskFactors struct {
+	Sensitivity  float64 `json:"sensitivity"`   // S: highest sensitivity weight times confidence
+	Volume       float64 `json:"volume"`        // V: summed scores W as W/(W+10)
+	CoOccurrence float64 `json:"co_occurrence"` // C: 1-0.5^(k-1) for k identifier types together
+	PHIContext   float64 `json:"phi_context"`   // P: clinical codes alongside direct identifiers
+	Derivation   string  `json:"derivation"`
+}
+
+// sensitivity returns the weight for d's type.
+func sensitivity(d PIIDetection) float64 {
+	if d.Type == "secret" {
+		if w, ok := secretSensitivity[d.Severity]; ok {
+			return w
+		}
+	} else if w, ok := sensitivityWeights[d.Type]; ok {
+		return w
+	}
+	return defaultSensitivity
+}
+
+// scoreRisk sets RiskScore, ConfidenceScore and RiskFactors as RiskModel describes,
+// given the summed confidence of the file's clinical codes.
+func (f *FileAttributes) scoreRisk(clinical float64) {
+	var factors RiskFactors
+	total, weights := 0.0, 0.0
+	records := map[string]map[string]bool{} // direct identifier types by record
+	count := 0
+
+	for _, detections := range [][]PIIDetection{f.PIIDetections, f.PHIDetections, f.SecretDetections} {
+		for _, d := range detections {
+			w := sensitivity(d)
+			factors.Sensitivity = max(factors.Sensitivity, w*d.Confidence)
+			total += w * d.Confidence
+			weights += w
+			count++
+
+			if directIdentifierTypes[d.Type] {
+				key := recordKey(d)
+				if records[key] == nil {
+					records[key] = map[string]bool{}
+				}
+				records[key][d.Type] = true
+			}
+		}
+	}
+
+	factors.Volume = total / (total + volumeHalfPoint)
+	for _, types := range records {
+		factors.CoOccurrence = max(factors.CoOccurrence, 1-math.Pow(0.5, float64(len(types)-1)))
+	}
+	if len(records) > 0 {
+		factors.PHIContext = min(clinical, 1)
+	}
+
+	f.RiskScore = 1 - (1-factors.Sensitivity)*(1-volumeWeight*factors.Volume)*
+		(1-coOccurrenceWeight*factors.CoOccurrence)*(1-phiContextWeight*factors.PHIContext)
+	f.ConfidenceScore = 0
+	if weights > 0 {
+		f.ConfidenceScore = total / weights
+	}
+
+	factors.Derivation = fmt.Sprintf("risk_score = 1 - (1-%.3f)(1-%.1f*%.3f)(1-%.1f*%.3f)(1-%.1f*%.3f) = %.3f; "+
+		"confidence_score = weighted mean of %d detection confidences = %.3f",
+		factors.Sensitivity, volumeWeight, factors.Volume, coOccurrenceWeight, factors.CoOccurrence,
+		phiContextWeight, factors.PHIContext, f.RiskScore, count, f.ConfidenceScore)
+	f.RiskFactors = factors
+}
+
+// recordKey identifies the record d was found in, so identifiers in different cells
+// of one row count as found together: the row for CSV, SQL, spreadsheet and document
+// table cells, the enclosing object or array for JSON values, and the line for
+// everything else.
+func recordKey(d PIIDetection) string {
+	loc := d.Location
+	if len(loc) > 0 && loc[0] == '$' {
+		return jsonMemberRegex.ReplaceAllString(loc, "")
+	}
+	for _, re := range []*regexp.Regexp{csvCellRegex, tableCellRegex, sqlCellRegex, sheetCellRegex} {
+		if m := re.FindStringSubmatch(loc); m != nil {
+			return strings.Join(m[1:], " ")
+		}
+	}
+	return fmt.Sprintf("%s:%d", loc, d.LineNumber)
+}
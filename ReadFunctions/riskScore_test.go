@@ -0,0 +1,95 @@
+package ReadFunctions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScoreRisk(t *testing.T) {
+	repeat := func(d PIIDetection, n int) []PIIDetection {
+		var out []PIIDetection
+		for i := 0; i < n; i++ {
+			d.LineNumber = i + 1
+			out = append(out, d)
+		}
+		return out
+	}
+	score := func(detections []PIIDetection) FileAttributes {
+		var f FileAttributes
+		f.addDetections(detections)
+		f.assess()
+		return f
+	}
+
+	email := PIIDetection{Type: "email", Confidence: 0.9, LineNumber: 1}
+	ssn := PIIDetection{Type: "ssn", Confidence: 0.9, LineNumber: 1}
+	name := PIIDetection{Type: "name", Confidence: 0.7, LineNumber: 1}
+	dob := PIIDetection{Type: "dob", Confidence: 0.85, LineNumber: 1}
+	icd10 := PIIDetection{Type: "icd10", Confidence: 0.9, LineNumber: 2}
+
+	comparisons := []struct {
+		lower, higher []PIIDetection
+		comment       string
+	}{
+		{[]PIIDetection{email}, []PIIDetection{ssn}, "An SSN is more sensitive than an email address"},
+		{repeat(email, 1), repeat(email, 50), "More records raise risk"},
+		{[]PIIDetection{name, dob, {Type: "ssn", Confidence: 0.9, LineNumber: 3}}, []PIIDetection{name, dob, ssn},
+			"Name, date of birth and SSN together outrank the same identifiers apart"},
+		{
+			[]PIIDetection{{Type: "name", Confidence: 0.7, Location: "row 2 c1 (name)"}, {Type: "ssn", Confidence: 0.9, Location: "row 3 c2 (ssn)"}},
+			[]PIIDetection{{Type: "name", Confidence: 0.7, Location: "row 2 c1 (name)"}, {Type: "ssn", Confidence: 0.9, Location: "row 2 c2 (ssn)"}},
+			"Cells of one CSV row are one record",
+		},
+		{[]PIIDetection{name, dob}, []PIIDetection{name, dob, icd10}, "Clinical codes make identifiers PHI"},
+		{[]PIIDetection{icd10}, []PIIDetection{name}, "Clinical codes alone identify nobody"},
+	}
+	for _, c := range comparisons {
+		lower, higher := score(c.lower).RiskScore, score(c.higher).RiskScore
+		if lower >= higher {
+			t.Errorf("RiskScore %.3f >= %.3f; want the second higher (%s)", lower, higher, c.comment)
+		}
+	}
+
+	f := score([]PIIDetection{name, dob, ssn, {Type: "secret", Severity: "critical", Confidence: 0.99}})
+	if f.RiskScore <= 0.99 || f.RiskScore > 1 {
+		t.Errorf("RiskScore with a critical secret = %.3f; want above 0.99", f.RiskScore)
+	}
+	want := (0.4*0.7 + 0.6*0.85 + 1*0.9 + 1*0.99) / (0.4 + 0.6 + 1 + 1)
+	if f.ConfidenceScore < want-1e-9 || f.ConfidenceScore > want+1e-9 {
+		t.Errorf("ConfidenceScore = %.3f; want %.3f", f.ConfidenceScore, want)
+	}
+	if f.RiskFactors.CoOccurrence != 0.75 || !strings.Contains(f.RiskFactors.Derivation, "risk_score = 1 - (1-0.990)") {
+		t.Errorf("RiskFactors = %+v; want co-occurrence 0.75 and the formula filled in", f.RiskFactors)
+	}
+
+	if empty := score(nil); empty.RiskScore != 0 || empty.ConfidenceScore != 0 {
+		t.Errorf("no detections: RiskScore %.3f ConfidenceScore %.3f; want 0 0", empty.RiskScore, empty.ConfidenceScore)
+	}
+}
+
+func TestRecordKey(t *testing.T) {
+	testCases := []struct {
+		first, second PIIDetection
+		same          bool
+		comment       string
+	}{
+		{PIIDetection{Location: "row 2 c1 (name)"}, PIIDetection{Location: "row 2 c3 (ssn)"}, true, "CSV cells in one row"},
+		{PIIDetection{Location: "row 2 c1 (name)"}, PIIDetection{Location: "row 12 c1 (name)"}, false, "CSV cells in different rows"},
+		{PIIDetection{Location: "public.users.name row 4"}, PIIDetection{Location: "public.users.ssn row 4"}, true, "SQL values in one row"},
+		{PIIDetection{Location: "users.name row 4"}, PIIDetection{Location: "orders.name row 4"}, false, "SQL rows of different tables"},
+		{PIIDetection{Location: "Sheet1!A4"}, PIIDetection{Location: "Sheet1!AB4"}, true, "Spreadsheet cells in one row"},
+		{PIIDetection{Location: "Sheet1!A4"}, PIIDetection{Location: "Sheet2!A4"}, false, "Spreadsheet rows on different sheets"},
+		{PIIDetection{Location: "word/document.xml table 1 row 2 cell 1"}, PIIDetection{Location: "word/document.xml table 1 row 2 cell 4"}, true, "Document table cells in one row"},
+		{PIIDetection{Location: "$.patients[0].name"}, PIIDetection{Location: "$.patients[0]['s.s.n']"}, true, "JSON members of one object"},
+		{PIIDetection{Location: "$.patients[0].name"}, PIIDetection{Location: "$.patients[1].name"}, false, "JSON members of different objects"},
+		{PIIDetection{Location: "page 1", LineNumber: 3}, PIIDetection{Location: "page 1", LineNumber: 3}, true, "Text on one line"},
+		{PIIDetection{LineNumber: 3}, PIIDetection{LineNumber: 4}, false, "Text on different lines"},
+	}
+
+	for _, tc := range testCases {
+		first, second := recordKey(tc.first), recordKey(tc.second)
+		if (first == second) != tc.same {
+			t.Errorf("recordKey = %q and %q; want same = %v (%s)", first, second, tc.same, tc.comment)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package ReadFunctions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ScanSummary aggregates the results of a directory scan.
+type ScanSummary struct {
+	FilesScanned      int `json:"files_scanned"`
+	FilesWithFindings int `json:"files_with_findings"`
+	FilesSkipped      int `json:"files_skipped"` // empty or unsupported file types
+	FilesErrored      int `json:"files_errored"`
+	TotalPIICount     int `json:"total_pii_count"`
+	TotalPHICount     int `json:"total_phi_count"`
+	TotalSecretCount  int `json:"total_secret_count"`
+}
+
+// Add folds a single file result into the summary.
+func (s *ScanSummary) Add(fileAttr FileAttributes) {
+	s.FilesScanned++
+	if fileAttr.Status == "error" {
+		s.FilesErrored++
+	}
+	if fileAttr.TotalPIICount > 0 || fileAttr.TotalPHICount > 0 || fileAttr.TotalSecretCount > 0 {
+		s.FilesWithFindings++
+	}
+	s.TotalPIICount += fileAttr.TotalPIICount
+	s.TotalPHICount += fileAttr.TotalPHICount
+	s.TotalSecretCount += fileAttr.TotalSecretCount
+}
+
+// ScanOptions controls how ScanDirectory spreads work across goroutines.
+type ScanOptions struct {
+	Workers     int           // files read concurrently, defaults to runtime.NumCPU()
+	QueueSize   int           // paths buffered between the walker and the workers, defaults to 2*Workers
+	FileTimeout time.Duration // per-file time limit, zero for none
+	Sorted      bool          // deliver results in path order instead of completion order
+	Exclude     []string      // files left out of the walk, such as the report being written
+}
+
+func (o ScanOptions) withDefaults() ScanOptions {
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 2 * o.Workers
+	}
+	return o
+}
+
+// ScanFile detects the type of a single file and reads it. Failures while reading are
+// recorded on the result with Status "error" so a directory scan can carry on; an
+// error is only returned when the file isn't something the readers support.
+func ScanFile(filePath string) (FileAttributes, error) {
+	return ScanFileContext(context.Background(), filePath)
+}
+
+// ScanFileContext is ScanFile with cancellation.
+func ScanFileContext(ctx context.Context, filePath string) (FileAttributes, error) {
+	fileAttr, err := DetectFileType(filePath)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedFileType) {
+			return fileAttr, err
+		}
+		return failedFile(filePath, fileAttr, err), nil
+	}
+
+	result, err := ReadFileContext(ctx, fileAttr)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedFileType) {
+			return result, err
+		}
+		return failedFile(filePath, result, err), nil
+	}
+
+	return result, nil
+}
+
+type scanJob struct {
+	seq  int
+	path string
+	err  error // walk error for path, reported without reading the file
+}
+
+type scanResult struct {
+	seq      int
+	fileAttr FileAttributes
+	skipped  bool
+}
+
+// ScanDirectory walks root recursively and scans every regular file beneath it using a
+// pool of workers. Each result is handed to sink as soon as it is ready (or, with
+// Sorted, as soon as every earlier path has been delivered) so memory use doesn't grow
+// with the number of files. sink is only ever called from one goroutine; returning an
+// error from it stops the scan.
+//
+// Unsupported and empty files are counted as skipped, and unreadable files or
+// directories are reported with Status "error" without stopping the walk.
+func ScanDirectory(ctx context.Context, root string, opts ScanOptions, sink func(FileAttributes) error) (ScanSummary, error) {
+	var summary ScanSummary
+	opts = opts.withDefaults()
+
+	if _, err := os.Stat(root); err != nil {
+		return summary, err
+	}
+	var excluded []os.FileInfo
+	for _, path := range opts.Exclude {
+		if info, err := os.Stat(path); err == nil {
+			excluded = append(excluded, info)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan scanJob, opts.QueueSize)
+	results := make(chan scanResult, opts.Workers)
+
+	// With Sorted, finished results wait for earlier paths before being delivered. The
+	// window caps how far the walker may run ahead of delivery, which bounds that buffer.
+	var window chan struct{}
+	if opts.Sorted {
+		window = make(chan struct{}, opts.QueueSize+opts.Workers)
+	}
+
+	walkDone := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		seq := 0
+		walkDone <- filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr == nil && (!d.Type().IsRegular() || isExcluded(d, excluded)) {
+				return nil
+			}
+
+			job := scanJob{seq: seq, path: path, err: walkErr}
+			seq++
+			if window != nil {
+				select {
+				case window <- struct{}{}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			if walkErr != nil && d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				res := scanResult{seq: job.seq}
+				if job.err != nil {
+					res.fileAttr = failedFile(job.path, FileAttributes{}, job.err)
+				} else {
+					res.fileAttr, res.skipped = scanWithTimeout(ctx, job.path, opts.FileTimeout)
+				}
+
+				select {
+				case results <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var sinkErr error
+	deliver := func(res scanResult) {
+		if window != nil {
+			<-window
+		}
+		if sinkErr != nil || ctx.Err() != nil {
+			return
+		}
+		if res.skipped {
+			summary.FilesSkipped++
+			return
+		}
+		summary.Add(res.fileAttr)
+		if err := sink(res.fileAttr); err != nil {
+			sinkErr = err
+			cancel()
+		}
+	}
+
+	pending := map[int]scanResult{}
+	next := 0
+	for res := range results {
+		if !opts.Sorted {
+			deliver(res)
+			continue
+		}
+		pending[res.seq] = res
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			deliver(ready)
+		}
+	}
+
+	walkErr := <-walkDone
+	switch {
+	case sinkErr != nil:
+		return summary, sinkErr
+	case walkErr != nil && !errors.Is(walkErr, context.Canceled):
+		return summary, walkErr
+	default:
+		return summary, ctx.Err()
+	}
+}
+
+// scanWithTimeout scans a single file under its own deadline, reporting whether the
+// file was skipped as unsupported. A panic while reading or scanning the file, such
+// as from a malformed document, fails that file rather than the whole scan.
+func scanWithTimeout(ctx context.Context, path string, timeout time.Duration) (fileAttr FileAttributes, skipped bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			fileAttr, skipped = failedFile(path, FileAttributes{}, fmt.Errorf("panic while scanning: %v", r)), false
+		}
+	}()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	fileAttr, err := ScanFileContext(ctx, path)
+	return fileAttr, errors.Is(err, ErrUnsupportedFileType)
+}
+
+// failedFile marks fileAttr as an error result for filePath.
+func failedFile(filePath string, fileAttr FileAttributes, err error) FileAttributes {
+	fileAttr.FilePath = filePath
+	fileAttr.Status = "error"
+	fileAttr.Errors = append(fileAttr.Errors, err.Error())
+	return fileAttr
+}
+
+// isExcluded reports whether d is one of the excluded files. os.SameFile is used so
+// the same file named by a relative path or a symlink is still recognised.
+func isExcluded(d fs.DirEntry, excluded []os.FileInfo) bool {
+	if len(excluded) == 0 {
+		return false
+	}
+	info, err := d.Info()
+	if err != nil {
+		return false
+	}
+	for _, e := range excluded {
+		if os.SameFile(info, e) {
+			return true
+		}
+	}
+	return false
+}
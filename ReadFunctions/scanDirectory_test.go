@@ -0,0 +1,187 @@
+package ReadFunctions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanDirectory(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"report.pdf":            minimalPDF,
+		"nested/deeper/doc.pdf": minimalPDF,
+		"nested/unknown.bin":    "\x00\x01\x02",
+		"empty.pdf":             "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var results []FileAttributes
+	summary, err := ScanDirectory(context.Background(), root, ScanOptions{Workers: 2}, func(f FileAttributes) error {
+		results = append(results, f)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanDirectory returned error: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Errorf("len(results) = %d; want 2", len(results))
+	}
+	if summary.FilesScanned != 2 {
+		t.Errorf("FilesScanned = %d; want 2", summary.FilesScanned)
+	}
+	if summary.FilesSkipped != 2 {
+		t.Errorf("FilesSkipped = %d; want 2", summary.FilesSkipped)
+	}
+	for _, r := range results {
+		if r.Status != "success" {
+			t.Errorf("%s Status = %q; want %q", r.FilePath, r.Status, "success")
+		}
+	}
+}
+
+func TestScanDirectoryExclude(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"doc.pdf", "goScan-report.pdf"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(minimalPDF), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var scanned []string
+	opts := ScanOptions{Workers: 2, Exclude: []string{filepath.Join(root, ".", "goScan-report.pdf")}}
+	summary, err := ScanDirectory(context.Background(), root, opts, func(f FileAttributes) error {
+		scanned = append(scanned, filepath.Base(f.FilePath))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanDirectory returned error: %v", err)
+	}
+	if len(scanned) != 1 || scanned[0] != "doc.pdf" || summary.FilesSkipped != 0 {
+		t.Errorf("scanned %v with %d skipped; want only doc.pdf", scanned, summary.FilesSkipped)
+	}
+}
+
+func TestScanDirectoryMissingRoot(t *testing.T) {
+	noop := func(FileAttributes) error { return nil }
+	if _, err := ScanDirectory(context.Background(), filepath.Join(t.TempDir(), "missing"), ScanOptions{}, noop); err == nil {
+		t.Error("ScanDirectory on a missing root returned no error")
+	}
+}
+
+func TestScanDirectorySorted(t *testing.T) {
+	root := t.TempDir()
+	var want []string
+	for i := range 50 {
+		path := filepath.Join(root, fmt.Sprintf("dir%d", i%3), fmt.Sprintf("file%02d.pdf", i))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(minimalPDF), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			want = append(want, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	_, err = ScanDirectory(context.Background(), root, ScanOptions{Workers: 8, QueueSize: 1, Sorted: true}, func(f FileAttributes) error {
+		got = append(got, f.FilePath)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanDirectory returned error: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d results; want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("result %d = %s; want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestScanDirectorySinkError(t *testing.T) {
+	root := t.TempDir()
+	for i := range 20 {
+		if err := os.WriteFile(filepath.Join(root, fmt.Sprintf("f%02d.pdf", i)), []byte(minimalPDF), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	_, err := ScanDirectory(context.Background(), root, ScanOptions{Workers: 4}, func(FileAttributes) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("ScanDirectory error = %v; want %v", err, stop)
+	}
+	if calls != 1 {
+		t.Errorf("sink called %d times after returning an error; want 1", calls)
+	}
+}
+
+func TestScanDirectoryPanic(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"crash.txt":  "this line makes the scanner panic\n",
+		"report.pdf": minimalPDF,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	SetScanner(func(seg Segment) ([]PIIDetection, error) {
+		if strings.Contains(seg.Text, "panic") {
+			panic("scanner exploded")
+		}
+		return nil, nil
+	})
+	defer SetScanner(nil)
+
+	status := map[string]FileAttributes{}
+	_, err := ScanDirectory(context.Background(), root, ScanOptions{Workers: 2}, func(f FileAttributes) error {
+		status[filepath.Base(f.FilePath)] = f
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanDirectory returned error: %v", err)
+	}
+
+	if got := status["report.pdf"].Status; got != "success" {
+		t.Errorf("report.pdf Status = %q; want %q", got, "success")
+	}
+	crash := status["crash.txt"]
+	if crash.Status != "error" {
+		t.Errorf("crash.txt Status = %q; want %q", crash.Status, "error")
+	}
+	if len(crash.Errors) == 0 || !strings.Contains(crash.Errors[0], "scanner exploded") {
+		t.Errorf("crash.txt Errors = %q; want the panic message", crash.Errors)
+	}
+}
@@ -0,0 +1,233 @@
+package ReadFunctions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Segment is a piece of extracted text handed to the registered Scanner, along with
+// where it came from in the original file.
+type Segment struct {
+	Text       string
+	LineNumber int    // 1-based line number, 0 when the source has no notion of lines
+	Offset     int    // byte offset of Text within the file, or within Location when set
+	Location   string // where Text sits in a structured document, copied to PIIDetection.Location
+	Field      string // column header or key name Text was stored under, used as context
+}
+
+// Scanner inspects a Segment and returns any detections found in it. Offsets in the
+// returned detections are shifted by seg.Offset, so they share its origin.
+type Scanner func(seg Segment) ([]PIIDetection, error)
+
+// ErrNoScanner is returned by the readers when no Scanner has been registered.
+var ErrNoScanner = errors.New("no scanner registered, call ReadFunctions.SetScanner first")
+
+var (
+	scannerMu         sync.RWMutex
+	scanner           Scanner
+	minConfidence     float64
+	revealCardNumbers bool
+)
+
+// SetScanner registers the function used by the readers to find sensitive data. The
+// readers only extract text; detection lives in RegexProcessing, which imports this
+// package, so main wires the two together with SetScanner(RegexProcessing.ScanSegment).
+func SetScanner(s Scanner) {
+	scannerMu.Lock()
+	defer scannerMu.Unlock()
+	scanner = s
+}
+
+// SetMinConfidence sets the confidence a detection needs to be kept. Raising it trades
+// recall for precision; the default, 0, keeps every detection.
+func SetMinConfidence(c float64) {
+	scannerMu.Lock()
+	defer scannerMu.Unlock()
+	minConfidence = c
+}
+
+// SetRevealCardNumbers chooses whether payment card numbers are reported in full. By
+// default a card's Value is its redacted form, first six and last four digits, and
+// the number is masked wherever else it appears in the results, such as the
+// content preview, to keep reports out of PCI scope. Secrets are always redacted.
+func SetRevealCardNumbers(reveal bool) {
+	scannerMu.Lock()
+	defer scannerMu.Unlock()
+	revealCardNumbers = reveal
+}
+
+// scanSegment runs the registered Scanner over seg, dropping detections below the
+// SetMinConfidence threshold before any reader counts them. Document keywords are
+// always kept, since they classify the file rather than being reported.
+func scanSegment(seg Segment) ([]PIIDetection, error) {
+	scannerMu.RLock()
+	s, threshold := scanner, minConfidence
+	scannerMu.RUnlock()
+
+	if s == nil {
+		return nil, ErrNoScanner
+	}
+	detections, err := s(seg)
+	kept := detections[:0]
+	for _, d := range detections {
+		if d.Type == "keyword" || d.Confidence >= threshold {
+			kept = append(kept, d)
+		}
+	}
+	return kept, err
+}
+
+// scanSegments runs the registered Scanner over each segment in turn.
+func scanSegments(ctx context.Context, segments []Segment) ([]PIIDetection, error) {
+	var detections []PIIDetection
+	for _, seg := range segments {
+		if err := ctx.Err(); err != nil {
+			return detections, err
+		}
+		found, err := scanSegment(seg)
+		if err != nil {
+			return detections, err
+		}
+		detections = append(detections, found...)
+	}
+	return detections, nil
+}
+
+// segmentsText joins the text of segments, for content previews.
+func segmentsText(segments []Segment) string {
+	var b previewBuilder
+	for _, seg := range segments {
+		b.add(seg.Text)
+	}
+	return b.String()
+}
+
+// maxPreviewText is how much text previewBuilder keeps, plenty for a 200 character preview.
+const maxPreviewText = 4096
+
+// previewBuilder joins the text a reader finds with spaces, for contentPreview,
+// keeping only the first maxPreviewText bytes or so.
+type previewBuilder struct {
+	b strings.Builder
+}
+
+func (p *previewBuilder) add(text string) {
+	if p.b.Len() >= maxPreviewText {
+		return
+	}
+	if p.b.Len() > 0 {
+		p.b.WriteByte(' ')
+	}
+	p.b.WriteString(text)
+}
+
+func (p *previewBuilder) String() string { return p.b.String() }
+
+// phiTypes are the detection types that identify health care records, providers or
+// coverage rather than a person in general, and the clinical codes that describe
+// care. addDetections files them as PHI.
+var phiTypes = map[string]bool{
+	"mrn": true, "npi": true, "dea": true, "mbi": true, "health_plan": true,
+	"icd10": true, "cpt": true, "ndc": true, "loinc": true,
+}
+
+// addDetections appends detections to the file results, PHI types to PHIDetections,
+// secrets to SecretDetections and the rest to PIIDetections, and keeps the totals
+// in sync. Document keywords are kept aside for assess rather than reported, and
+// secrets and, unless SetRevealCardNumbers is on, card numbers are reported redacted.
+func (f *FileAttributes) addDetections(detections []PIIDetection) {
+	scannerMu.RLock()
+	reveal := revealCardNumbers
+	scannerMu.RUnlock()
+
+	for _, d := range detections {
+		if d.Type == "credit_card" && !reveal {
+			f.hide(&d)
+		}
+		switch {
+		case d.Type == "keyword":
+			f.keywords = append(f.keywords, d)
+		case d.Type == "secret":
+			f.hide(&d)
+			f.SecretDetections = append(f.SecretDetections, d)
+		case phiTypes[d.Type]:
+			f.PHIDetections = append(f.PHIDetections, d)
+		default:
+			f.PIIDetections = append(f.PIIDetections, d)
+		}
+	}
+	f.TotalPIICount = len(f.PIIDetections)
+	f.TotalPHICount = len(f.PHIDetections)
+	f.TotalSecretCount = len(f.SecretDetections)
+}
+
+// minMaskedOverlap is the shortest part of a hidden value, cut short at the edge of a
+// preview or context, that hideValues masks; shorter overlaps are likely chance.
+const minMaskedOverlap = 4
+
+// hide replaces d's Value with its RedactedValue and drops its Context, keeping the
+// value aside so hideValues can mask it everywhere else it was copied. A PEM block
+// reported without its end line, such as a private key in a .pem file read a line at
+// a time, has a body no detection covers, so the file's preview is dropped instead.
+func (f *FileAttributes) hide(d *PIIDetection) {
+	if strings.HasPrefix(d.Value, "-----BEGIN ") && !strings.Contains(d.Value, "-----END ") {
+		f.unmasked = true
+	}
+	f.hidden = append(f.hidden, [2]string{d.Value, d.RedactedValue})
+	d.Value, d.Context = d.RedactedValue, ""
+}
+
+// hideValues masks the values hide kept aside in the content preview and in the
+// Context of every detection, so they appear nowhere in the results. The preview is
+// dropped when hide found a value it can't mask.
+func (f *FileAttributes) hideValues() {
+	if f.unmasked {
+		f.ContentPreview = ""
+	}
+	for _, h := range f.hidden {
+		f.ContentPreview = maskValue(f.ContentPreview, h[0], h[1])
+		for _, detections := range [][]PIIDetection{f.PIIDetections, f.PHIDetections, f.SecretDetections} {
+			for i := range detections {
+				detections[i].Context = maskValue(detections[i].Context, h[0], h[1])
+			}
+		}
+	}
+}
+
+// maskValue replaces value with redacted, which must be as long, throughout text. A
+// text that ends with the start of value or starts with its end, because a preview
+// or context cut it short, has that part masked too.
+func maskValue(text, value, redacted string) string {
+	if value == "" || len(redacted) != len(value) {
+		return text
+	}
+	text = strings.ReplaceAll(text, value, redacted)
+	for k := min(len(value)-1, len(text)); k >= minMaskedOverlap; k-- {
+		if strings.HasSuffix(text, value[:k]) {
+			text = text[:len(text)-k] + redacted[:k]
+			break
+		}
+	}
+	for k := min(len(value)-1, len(text)); k >= minMaskedOverlap; k-- {
+		if strings.HasPrefix(text, value[len(value)-k:]) {
+			text = redacted[len(value)-k:] + text[k:]
+			break
+		}
+	}
+	return text
+}
+
+// contentPreview returns the first 200 characters of content without splitting a rune.
+func contentPreview(content string) string {
+	const previewLen = 200
+	count := 0
+	for i := range content {
+		if count == previewLen {
+			return content[:i]
+		}
+		count++
+	}
+	return content
+}
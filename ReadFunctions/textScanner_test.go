@@ -0,0 +1,105 @@
+package ReadFunctions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddDetectionsRoutes(t *testing.T) {
+	var f FileAttributes
+	f.addDetections([]PIIDetection{{Type: "ssn"}, {Type: "mrn"}, {Type: "email"}})
+	f.addDetections([]PIIDetection{{Type: "npi"}, {Type: "secret", Severity: "critical"}})
+
+	if f.TotalPIICount != 2 || len(f.PIIDetections) != 2 {
+		t.Errorf("PII count = %d (%d detections); want 2", f.TotalPIICount, len(f.PIIDetections))
+	}
+	if f.TotalPHICount != 2 || len(f.PHIDetections) != 2 {
+		t.Errorf("PHI count = %d (%d detections); want 2", f.TotalPHICount, len(f.PHIDetections))
+	}
+	if f.TotalSecretCount != 1 || len(f.SecretDetections) != 1 {
+		t.Errorf("secret count = %d (%d detections); want 1", f.TotalSecretCount, len(f.SecretDetections))
+	}
+	for _, d := range f.PHIDetections {
+		if !phiTypes[d.Type] {
+			t.Errorf("%s filed as PHI", d.Type)
+		}
+	}
+}
+
+func TestScanSegmentMinConfidence(t *testing.T) {
+	SetMinConfidence(0.5)
+	defer SetMinConfidence(0)
+	SetScanner(func(seg Segment) ([]PIIDetection, error) {
+		return []PIIDetection{
+			{Type: "ssn", Confidence: 0.4, Location: seg.Location},
+			{Type: "email", Confidence: 0.9, Location: seg.Location},
+			{Type: "npi", Confidence: 0.5, Location: seg.Location},
+			{Type: "keyword", Confidence: 0.2, Location: seg.Location},
+		}, nil
+	})
+	defer SetScanner(nil)
+
+	detections, err := scanSegment(Segment{Text: "x"})
+	if err != nil {
+		t.Fatalf("scanSegment returned error: %v", err)
+	}
+	var types []string
+	for _, d := range detections {
+		types = append(types, d.Type)
+	}
+	if got := strings.Join(types, ","); got != "email,npi,keyword" {
+		t.Errorf("kept %s; want email,npi,keyword, a detection at the threshold and keywords are kept", got)
+	}
+
+	result, err := ReadCSVFile(strings.NewReader("id\nA\nB\n"))
+	if err != nil {
+		t.Fatalf("ReadCSVFile returned error: %v", err)
+	}
+	if len(result.Columns) != 1 || result.Columns[0].TypeCounts["ssn"] != 0 {
+		t.Errorf("column summaries = %+v; want one without the ssn detections below the threshold", result.Columns)
+	}
+}
+
+func TestAddDetectionsHidesCardNumbers(t *testing.T) {
+	card := PIIDetection{Type: "credit_card", Value: "4111111111111111", RedactedValue: "411111XXXXXX1111",
+		Context: "card 4111111111111111 on file"}
+	email := PIIDetection{Type: "email", Value: "a@b.co", Context: "a@b.co card 4111111111"}
+
+	var f FileAttributes
+	f.addDetections([]PIIDetection{card, email})
+	f.hideValues()
+	if got := f.PIIDetections[0]; got.Value != card.RedactedValue || got.Context != "" {
+		t.Errorf("card Value %q Context %q; want %q and no context", got.Value, got.Context, card.RedactedValue)
+	}
+	if got := f.PIIDetections[1].Context; got != "a@b.co card 411111XXXX" {
+		t.Errorf("email Context = %q; want the card number cut short masked", got)
+	}
+
+	SetRevealCardNumbers(true)
+	defer SetRevealCardNumbers(false)
+	var revealed FileAttributes
+	revealed.addDetections([]PIIDetection{card})
+	if got := revealed.PIIDetections[0].Value; got != card.Value {
+		t.Errorf("card Value with SetRevealCardNumbers = %q; want %q", got, card.Value)
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	testCases := []struct {
+		text    string
+		want    string
+		comment string
+	}{
+		{"token=ghp_abcdefgh;", "token=ghp_********;", "Whole value"},
+		{"a ghp_abcdefgh b ghp_abcdefgh", "a ghp_******** b ghp_********", "Every occurrence"},
+		{"token=ghp_abcd", "token=ghp_****", "Cut short at the end"},
+		{"cdefgh and more", "****** and more", "Cut short at the start"},
+		{"ends in gh", "ends in gh", "Overlap too short to be the value"},
+	}
+
+	for _, tc := range testCases {
+		if got := maskValue(tc.text, "ghp_abcdefgh", "ghp_********"); got != tc.want {
+			t.Errorf("maskValue(%q) = %q; want %q (%s)", tc.text, got, tc.want, tc.comment)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"goScan/ReadFunctions"
+)
+
+const (
+	// streetSuffixPattern lists USPS street suffixes and their common abbreviations.
+	streetSuffixPattern = `(?i:street|st|avenue|ave|av|boulevard|blvd|road|rd|drive|dr|lane|ln|court|ct|circle|cir|place|pl|` +
+		`terrace|ter|way|parkway|pkwy|highway|hwy|square|sq|trail|trl|plaza|plz|alley|aly|crescent|cres|` +
+		`expressway|expy|freeway|fwy|loop|pike|turnpike|tpke|row|run|path|walk|point|pt|crossing|xing|` +
+		`heights|hts|hill|ridge|rdg|pass|cove|cv|glen|grove|grv|landing|ldg|meadow|mdw|park|estates|est)`
+
+	// directionalPattern matches a street direction such as "N", "S.", "NW" or "West".
+	directionalPattern = `(?:[NSEW]|NE|NW|SE|SW|North|South|East|West)\.?`
+
+	// unitPattern matches a secondary unit such as ", Apt 4B", "Suite 400" or "#12".
+	unitPattern = `(?:,?\s*(?:(?i:apt|apartment|suite|ste|unit|rm|room|fl|floor|bldg|building|lot|trlr|spc|space|dept)\.?\s*#?\s*|#\s*)` +
+		`[A-Za-z0-9]{1,6}(?:-[A-Za-z0-9]{1,6})?\b)?`
+)
+
+var (
+	// addressKeywordRegex finds the words that label a home or mailing address, and
+	// businessAddressRegex those that show an address belongs to a business.
+	addressKeywordRegex  = regexp.MustCompile(`(?i)\b(address|addr|mailing|residence|resides|lives\s+at|home|ship\s+to|bill\s+to)\b`)
+	businessAddressRegex = regexp.MustCompile(`(?i)\b(office|offices|headquarters|hq|branch|store|warehouse|campus|factory)\b`)
+
+	// streetRegex matches a house number, up to four capitalised or ordinal street
+	// name words and a suffix, with optional directions and unit: "1600 Pennsylvania
+	// Avenue NW", "42 W. 5th St, Apt 4B". Street names must be capitalised so that
+	// prose such as "2 cats on the road" doesn't match.
+	streetRegex = regexp.MustCompile(`\b\d{1,6}(?:-\d{1,4})?[A-Za-z]?\s+(?:` + directionalPattern + `\s+)?` +
+		`(?:(?:[A-Z][A-Za-z'-]*|\d+(?i:st|nd|rd|th))\s+){1,4}?` + streetSuffixPattern + `\b\.?` +
+		`(?:\s+` + directionalPattern + `\b)?` + unitPattern)
+
+	// poBoxRegex matches post office boxes: "PO Box 123", "P.O. Box 45", "Post Office Box 6".
+	poBoxRegex = regexp.MustCompile(`(?i)\b(?:p\.?\s*o\.?\s*box|post\s+office\s+box)\s*#?\s*\d{1,6}\b`)
+
+	// cityStateZIPRegex matches the last line of an address: a capitalised city of up
+	// to four words, a state abbreviation or name, and a ZIP or ZIP+4.
+	cityStateZIPRegex = regexp.MustCompile(`\b([A-Z][A-Za-z.'-]*(?:\s[A-Z][A-Za-z.'-]*){0,3}),?\s+` +
+		`([A-Z]{2}|[A-Z][a-z]+(?:\s[A-Z][a-z]+){0,2})\.?,?\s+(\d{5})(?:-(\d{4}))?\b`)
+
+	// addressGapRegex matches what may separate a street line from its city line.
+	addressGapRegex = regexp.MustCompile(`^[\s,;]*$`)
+)
+
+// zipRange is an inclusive range of three digit ZIP prefixes.
+type zipRange struct{ from, to int }
+
+// stateZIPs maps USPS state, territory and military codes to the ZIP prefixes
+// assigned to them.
+var stateZIPs = map[string][]zipRange{
+	"AL": {{350, 369}}, "AK": {{995, 999}}, "AZ": {{850, 865}}, "AR": {{716, 729}, {755, 755}},
+	"CA": {{900, 961}}, "CO": {{800, 816}}, "CT": {{60, 69}}, "DE": {{197, 199}},
+	"DC": {{200, 205}, {569, 569}}, "FL": {{320, 349}}, "GA": {{300, 319}, {398, 399}},
+	"HI": {{967, 968}}, "ID": {{832, 838}}, "IL": {{600, 629}}, "IN": {{460, 479}},
+	"IA": {{500, 528}}, "KS": {{660, 679}}, "KY": {{400, 427}}, "LA": {{700, 714}},
+	"ME": {{39, 49}}, "MD": {{206, 219}}, "MA": {{10, 27}, {55, 55}}, "MI": {{480, 499}},
+	"MN": {{550, 567}}, "MS": {{386, 397}}, "MO": {{630, 658}}, "MT": {{590, 599}},
+	"NE": {{680, 693}}, "NV": {{889, 898}}, "NH": {{30, 38}}, "NJ": {{70, 89}},
+	"NM": {{870, 884}}, "NY": {{5, 5}, {63, 63}, {100, 149}}, "NC": {{270, 289}},
+	"ND": {{580, 588}}, "OH": {{430, 459}}, "OK": {{730, 749}}, "OR": {{970, 979}},
+	"PA": {{150, 196}}, "RI": {{28, 29}}, "SC": {{290, 299}}, "SD": {{570, 577}},
+	"TN": {{370, 385}}, "TX": {{750, 799}, {885, 885}}, "UT": {{840, 847}}, "VT": {{50, 54}, {56, 59}},
+	"VA": {{201, 201}, {220, 246}}, "WA": {{980, 994}}, "WV": {{247, 268}}, "WI": {{530, 549}},
+	"WY": {{820, 831}},
+	"PR": {{6, 9}}, "VI": {{8, 8}}, "GU": {{969, 969}}, "AS": {{967, 967}}, "MP": {{969, 969}},
+	"FM": {{969, 969}}, "MH": {{969, 969}}, "PW": {{969, 969}},
+	"AA": {{340, 340}}, "AE": {{90, 98}}, "AP": {{962, 966}},
+}
+
+// stateNames maps lower case state and territory names to their USPS codes.
+var stateNames = map[string]string{
+	"alabama": "AL", "alaska": "AK", "arizona": "AZ", "arkansas": "AR", "california": "CA",
+	"colorado": "CO", "connecticut": "CT", "delaware": "DE", "district of columbia": "DC",
+	"florida": "FL", "georgia": "GA", "hawaii": "HI", "idaho": "ID", "illinois": "IL",
+	"indiana": "IN", "iowa": "IA", "kansas": "KS", "kentucky": "KY", "louisiana": "LA",
+	"maine": "ME", "maryland": "MD", "massachusetts": "MA", "michigan": "MI", "minnesota": "MN",
+	"mississippi": "MS", "missouri": "MO", "montana": "MT", "nebraska": "NE", "nevada": "NV",
+	"new hampshire": "NH", "new jersey": "NJ", "new mexico": "NM", "new york": "NY",
+	"north carolina": "NC", "north dakota": "ND", "ohio": "OH", "oklahoma": "OK", "oregon": "OR",
+	"pennsylvania": "PA", "rhode island": "RI", "south carolina": "SC", "south dakota": "SD",
+	"tennessee": "TN", "texas": "TX", "utah": "UT", "vermont": "VT", "virginia": "VA",
+	"washington": "WA", "west virginia": "WV", "wisconsin": "WI", "wyoming": "WY",
+	"puerto rico": "PR", "guam": "GU",
+}
+
+// addressDetector reports US postal addresses. Street lines, PO boxes and city,
+// state and ZIP lines are found separately; a street or PO box followed by a city
+// line, on the same line or the next within one piece of text, is reported as one
+// address with fullConfidence. Parts found on their own are reported with
+// partConfidence and Subtype "street", "po_box" or "city_state_zip". City lines
+// need a real state and a ZIP in that state's range, so "Springfield, IL 90210"
+// is ignored.
+type addressDetector struct {
+	partConfidence float64
+	fullConfidence float64
+}
+
+func (a addressDetector) Type() string { return "address" }
+
+func (a addressDetector) ContextRules() ContextRules {
+	return ContextRules{Positive: addressKeywordRegex, Negative: businessAddressRegex, Window: keywordWindow}
+}
+
+// addressPart is a street, PO box or city line found in the text.
+type addressPart struct {
+	start, end int
+	subtype    string
+}
+
+func (a addressDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var streets []addressPart
+	for _, loc := range streetRegex.FindAllStringIndex(line, -1) {
+		streets = append(streets, addressPart{loc[0], loc[1], "street"})
+	}
+	for _, loc := range poBoxRegex.FindAllStringIndex(line, -1) {
+		streets = append(streets, addressPart{loc[0], loc[1], "po_box"})
+	}
+	sort.Slice(streets, func(i, j int) bool { return streets[i].start < streets[j].start })
+
+	// City lines are looked for between the street lines, so a street name is never
+	// mistaken for the start of a city.
+	var parts []addressPart
+	from := 0
+	for _, street := range append(streets, addressPart{start: len(line)}) {
+		if street.start < from {
+			continue // overlaps the previous street line
+		}
+		parts = append(parts, findCityLines(line, from, street.start)...)
+		if street.subtype != "" {
+			parts = append(parts, street)
+		}
+		from = street.end
+	}
+
+	var detections []ReadFunctions.PIIDetection
+	for i := 0; i < len(parts); i++ {
+		part, confidence := parts[i], a.partConfidence
+		if part.subtype != "city_state_zip" && i+1 < len(parts) && parts[i+1].subtype == "city_state_zip" &&
+			addressGapRegex.MatchString(line[part.end:parts[i+1].start]) {
+			part = addressPart{start: part.start, end: parts[i+1].end}
+			confidence = a.fullConfidence
+			i++
+		}
+
+		match := line[part.start:part.end]
+		detections = append(detections, ReadFunctions.PIIDetection{
+			Type:          a.Type(),
+			Subtype:       part.subtype,
+			Value:         match,
+			RedactedValue: redactAll(match),
+			StartOffset:   part.start,
+			EndOffset:     part.end,
+			Confidence:    confidence,
+		})
+	}
+
+	return detections
+}
+
+// findCityLines returns the valid city, state and ZIP lines in line[from:to].
+func findCityLines(line string, from, to int) []addressPart {
+	var parts []addressPart
+	for _, loc := range cityStateZIPRegex.FindAllStringSubmatchIndex(line[from:to], -1) {
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = line[from+loc[2*i] : from+loc[2*i+1]]
+			}
+		}
+		if validStateZIP(m[2], m[3], m[4]) {
+			parts = append(parts, addressPart{from + loc[0], from + loc[1], "city_state_zip"})
+		}
+	}
+	return parts
+}
+
+// validStateZIP reports whether state is a USPS code or state name and zip falls in
+// a range assigned to it. A ZIP+4 add-on of 0000 is never assigned.
+func validStateZIP(state, zip, plus4 string) bool {
+	code := state
+	if len(state) != 2 {
+		code = stateNames[strings.ToLower(state)]
+	}
+	if plus4 == "0000" {
+		return false
+	}
+
+	prefix := atoi(zip[:3])
+	for _, r := range stateZIPs[code] {
+		if prefix >= r.from && prefix <= r.to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestAddressDetector(t *testing.T) {
+	testCases := []struct {
+		text       string
+		value      string // "" when no address should be found
+		subtype    string
+		confidence float64
+		comment    string
+	}{
+		{"Ship to 123 Main St, Springfield, IL 62704 by Friday", "123 Main St, Springfield, IL 62704", "", 0.9, "Full address on one line"},
+		{"1600 Pennsylvania Avenue NW", "1600 Pennsylvania Avenue NW", "street", 0.6, "Trailing direction"},
+		{"42 W. 5th St, Apt 4B", "42 W. 5th St, Apt 4B", "street", 0.6, "Ordinal street name and unit"},
+		{"350 Fifth Ave Suite 3400 New York, NY 10118-0110", "350 Fifth Ave Suite 3400 New York, NY 10118-0110", "", 0.9, "Suite and ZIP+4"},
+		{"PO Box 1234\nAustin, TX 78701", "PO Box 1234\nAustin, TX 78701", "", 0.9, "PO box with city on the next line"},
+		{"mail P.O. Box 77", "P.O. Box 77", "po_box", 0.6, "Dotted PO box"},
+		{"Seattle, WA 98101", "Seattle, WA 98101", "city_state_zip", 0.6, "City line alone"},
+		{"Albany, New York 12207", "Albany, New York 12207", "city_state_zip", 0.6, "State name"},
+		{"San Juan, PR 00901", "San Juan, PR 00901", "city_state_zip", 0.6, "Territory with leading zero ZIP"},
+
+		{"Springfield, IL 90210", "", "", 0, "ZIP belongs to another state"},
+		{"Andover, MA 05501", "Andover, MA 05501", "city_state_zip", 0.6, "055 is a Massachusetts prefix"},
+		{"Andover, VT 05501", "", "", 0, "055 isn't a Vermont prefix"},
+		{"Burlington, VT 05401", "Burlington, VT 05401", "city_state_zip", 0.6, "Vermont ZIP"},
+		{"Springfield, ZZ 62704", "", "", 0, "Unknown state"},
+		{"Austin, TX 78701-0000", "", "", 0, "ZIP+4 add-on never assigned"},
+		{"we saw 2 cats on the road", "", "", 0, "Lower case prose"},
+		{"Invoice 12345 total due", "", "", 0, "Number without a street"},
+	}
+
+	detector := addressDetector{partConfidence: 0.6, fullConfidence: 0.9}
+	for _, tt := range testCases {
+		detections := detector.Detect(tt.text)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("Detect(%q) = %q; want no match (%s)", tt.text, detections[0].Value, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("Detect(%q) returned %d detections; want 1 (%s)", tt.text, len(detections), tt.comment)
+			continue
+		}
+		d := detections[0]
+		if d.Value != tt.value || d.Subtype != tt.subtype || d.Confidence != tt.confidence {
+			t.Errorf("Detect(%q) = %q subtype %q confidence %.2f; want %q %q %.2f (%s)",
+				tt.text, d.Value, d.Subtype, d.Confidence, tt.value, tt.subtype, tt.confidence, tt.comment)
+		}
+	}
+}
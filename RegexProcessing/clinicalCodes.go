@@ -0,0 +1,57 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// icd10Regex matches ICD-10-CM diagnosis codes: a letter, a digit, a digit (or A or
+	// B, as in C4A) and up to four more characters, usually after a dot: E11.9, S72.001A.
+	icd10Regex        = regexp.MustCompile(`\b[A-Z]\d[0-9AB](?:\.?[0-9A-Z]{1,4})?\b`)
+	icd10KeywordRegex = regexp.MustCompile(`(?i)\b(icd|icd-?10(?:-cm)?|diagnos[ie]s|dx|diag|condition)\b`)
+
+	// cptRegex matches CPT procedure codes: five digits, or four digits and F, T or U
+	// for Category II, Category III and proprietary laboratory codes.
+	cptRegex        = regexp.MustCompile(`\b\d{4}[0-9FTU]\b`)
+	cptKeywordRegex = regexp.MustCompile(`(?i)\b(cpt|hcpcs|procedure|proc|px|billing\s+code)\b`)
+
+	// ndcRegex matches National Drug Codes in the 4-4-2, 5-3-2 and 5-4-1 layouts.
+	ndcRegex        = regexp.MustCompile(`\b(?:\d{4}-\d{4}-\d{2}|\d{5}-\d{3}-\d{2}|\d{5}-\d{4}-\d)\b`)
+	ndcKeywordRegex = regexp.MustCompile(`(?i)\b(ndc|national\s+drug|drug|rx|medication|dispensed|tablets?|capsules?|mg)\b`)
+
+	// loincRegex matches LOINC codes: up to five digits, a dash and a check digit.
+	loincRegex        = regexp.MustCompile(`\b\d{1,5}-\d\b`)
+	loincKeywordRegex = regexp.MustCompile(`(?i)\b(loinc|lab|labs|laboratory|test|panel|result|observation|specimen)\b`)
+)
+
+// icd10Weak reports codes written without the dot, such as I10 or E119, which look
+// like many other identifiers.
+func icd10Weak(code string) bool {
+	return !strings.Contains(code, ".")
+}
+
+// cptValid rejects 00000 and codes above 99607, the end of the CPT ranges.
+func cptValid(code string) bool {
+	if !isDigit(code[4]) {
+		return true
+	}
+	n, _ := strconv.Atoi(code)
+	return n >= 100 && n <= 99607
+}
+
+// cptWeak reports plain five digit codes, which are mostly ZIP codes and amounts.
+func cptWeak(code string) bool {
+	return isDigit(code[4])
+}
+
+// ndcValid rejects codes whose labeler segment is all zeros.
+func ndcValid(code string) bool {
+	return strings.Trim(code[:strings.IndexByte(code, '-')], "0") != ""
+}
+
+// loincValid checks a LOINC code's check digit, which uses the Luhn (mod 10) algorithm.
+func loincValid(code string) bool {
+	return luhnValid(strings.Replace(code, "-", "", 1))
+}
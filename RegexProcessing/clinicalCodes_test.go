@@ -0,0 +1,77 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestClinicalCodes(t *testing.T) {
+	testCases := []struct {
+		detectionType string
+		text          string
+		value         string // "" when nothing should be found
+		confidence    float64
+		comment       string
+	}{
+		{"icd10", "Diagnosis: E11.9 type 2 diabetes", "E11.9", 0.9, "ICD-10 with keyword"},
+		{"icd10", "fracture S72.001A", "S72.001A", 0.45, "Dotted code with 7th character, no keyword"},
+		{"icd10", "dx I10", "I10", 0.9, "Three character code with keyword"},
+		{"icd10", "Room I10 is free", "", 0, "Undotted code without keyword"},
+		{"icd10", "C4A.9 melanoma, diagnosis confirmed", "C4A.9", 0.9, "Letter in the third position"},
+		{"cpt", "CPT 99213 office visit", "99213", 0.85, "CPT with keyword"},
+		{"cpt", "Springfield 62704", "", 0, "Plain five digits without keyword"},
+		{"cpt", "procedure 00001", "", 0, "Below the CPT range"},
+		{"cpt", "measure 0001F reported", "0001F", 0.4, "Category II code without keyword"},
+		{"ndc", "NDC 0777-3105-02", "0777-3105-02", 0.9, "4-4-2 NDC with keyword"},
+		{"ndc", "item 12345-678-90", "12345-678-90", 0.6, "5-3-2 NDC without keyword"},
+		{"ndc", "Rx 50090-2875-1", "50090-2875-1", 0.9, "5-4-1 NDC"},
+		{"ndc", "00000-123-45", "", 0, "Zero labeler"},
+		{"loinc", "LOINC 2345-7 glucose", "2345-7", 0.85, "LOINC with keyword"},
+		{"loinc", "lab 4548-4 HbA1c", "4548-4", 0.85, "Lab keyword"},
+		{"loinc", "lab 2345-8", "", 0, "Wrong check digit"},
+		{"loinc", "pages 2345-7", "", 0, "No keyword"},
+	}
+
+	for _, tt := range testCases {
+		var detector Detector
+		for _, d := range Detectors() {
+			if d.Type() == tt.detectionType {
+				detector = d
+			}
+		}
+		if detector == nil {
+			t.Fatalf("no %s detector registered", tt.detectionType)
+		}
+
+		detections := detector.Detect(tt.text)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("%s Detect(%q) = %q; want no match (%s)", tt.detectionType, tt.text, detections[0].Value, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("%s Detect(%q) returned %d detections; want 1 (%s)", tt.detectionType, tt.text, len(detections), tt.comment)
+			continue
+		}
+		if d := detections[0]; d.Value != tt.value || d.Confidence != tt.confidence {
+			t.Errorf("%s Detect(%q) = %q confidence %.2f; want %q %.2f (%s)",
+				tt.detectionType, tt.text, d.Value, d.Confidence, tt.value, tt.confidence, tt.comment)
+		}
+	}
+}
+
+func TestClinicalCodeField(t *testing.T) {
+	detections, err := checkField("99213", "cpt_code", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range detections {
+		if d.Type == "cpt" {
+			if d.Confidence != 0.85 {
+				t.Errorf("cpt under cpt_code has confidence %.2f; want 0.85", d.Confidence)
+			}
+			return
+		}
+	}
+	t.Errorf("checkField(99213, cpt_code) found no cpt code")
+}
@@ -0,0 +1,102 @@
+package RegexProcessing
+
+import (
+	"math"
+	"regexp"
+)
+
+// ContextRules are the words around a match that make it more or less likely to be
+// what a detector says it is: "call" before ten digits suggests a phone number,
+// "order" suggests it isn't. Every built-in detector declares them except
+// keywordDetector, whose Confidence is a weight rather than a likelihood.
+//
+// Many detectors already look for a label in Detect, either to choose between a
+// base and a keyword confidence or because some matches are only credible with a
+// label, such as nine bare digits as an SSN. Those leave Positive nil, so the label
+// doesn't count twice, and use Negative for the words that label something else.
+type ContextRules struct {
+	Positive *regexp.Regexp // words that support a match, nil for none
+	Negative *regexp.Regexp // words that count against a match, nil for none
+	Window   int            // bytes either side of the match the words are looked for in
+}
+
+// ContextDetector is implemented by detectors whose matches are scored against the
+// words around them. checkField adjusts the Confidence Detect reported, which
+// reflects how specific the pattern is and whether the match passed validation, by
+// how close the nearest positive and negative keywords are.
+type ContextDetector interface {
+	Detector
+	ContextRules() ContextRules
+}
+
+var (
+	// sampleDataRegex finds the words that mark a value as made up for testing or
+	// documentation; issuers and providers publish sample numbers and keys that pass
+	// every check.
+	sampleDataRegex = regexp.MustCompile(`(?i)\b(test|testing|sample|example|dummy|fake|sandbox)\b`)
+	// referenceNumberRegex finds the words that label order, part and other reference
+	// numbers, which are easily mistaken for identifiers and codes made of digits.
+	referenceNumberRegex = regexp.MustCompile(`(?i)\b(order|invoice|tracking|serial|part|sku|model|ticket|confirmation|ref|reference|zip|postal)\b`)
+)
+
+const (
+	// positiveWeight is how much of the gap to 1 a positive keyword right next to a
+	// match closes.
+	positiveWeight = 0.6
+	// negativeWeight is how much of the confidence a negative keyword right next to a
+	// match takes away.
+	negativeWeight = 0.6
+)
+
+// scoreContext combines a detection's confidence with keyword proximity. A keyword
+// adjacent to the match counts fully and one at the edge of the window barely at
+// all: with proximity p, a positive keyword raises c to c + (1-c)*0.6*p and a
+// negative keyword then lowers it to c - c*0.6*p. The result is rounded to two
+// places.
+func scoreContext(confidence float64, rules ContextRules, line string, start, end int) float64 {
+	if p := keywordProximity(rules.Positive, rules.Window, line, start, end); p > 0 {
+		confidence += (1 - confidence) * positiveWeight * p
+	}
+	if p := keywordProximity(rules.Negative, rules.Window, line, start, end); p > 0 {
+		confidence -= confidence * negativeWeight * p
+	}
+	return math.Round(confidence*100) / 100
+}
+
+// keywordProximity returns how close the nearest match of re is to line[start:end],
+// from 1 for a keyword touching it, or separated only by punctuation and spaces, down
+// to 0 for none within window bytes. Keywords inside the match itself don't count.
+func keywordProximity(re *regexp.Regexp, window int, line string, start, end int) float64 {
+	if re == nil || window <= 0 {
+		return 0
+	}
+
+	from := max(start-window, 0)
+	best := 0.0
+	for _, loc := range re.FindAllStringIndex(line[from:min(end+window, len(line))], -1) {
+		kStart, kEnd := from+loc[0], from+loc[1]
+		var gap string
+		switch {
+		case kEnd <= start:
+			gap = line[kEnd:start]
+		case kStart >= end:
+			gap = line[end:kStart]
+		default:
+			continue
+		}
+		best = max(best, 1-float64(wordBytes(gap))/float64(window))
+	}
+	return best
+}
+
+// wordBytes counts the letters and digits in s, so "Phone: " and "Phone " are
+// equally close to the number after them.
+func wordBytes(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if isAlnum(s[i]) {
+			n++
+		}
+	}
+	return n
+}
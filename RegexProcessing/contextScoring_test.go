@@ -0,0 +1,84 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestScoreContext(t *testing.T) {
+	rules := ContextRules{
+		Positive: regexp.MustCompile(`(?i)\b(phone|call)\b`),
+		Negative: regexp.MustCompile(`(?i)\border\b`),
+		Window:   20,
+	}
+
+	testCases := []struct {
+		line       string
+		confidence float64
+		want       float64
+		comment    string
+	}{
+		{"2024561414", 0.4, 0.4, "No keywords"},
+		{"Phone: 2024561414", 0.4, 0.76, "Positive keyword beside the match"},
+		{"2024561414 (call after five)", 0.4, 0.76, "Positive keyword after the match"},
+		{"call desk on 2024561414", 0.4, 0.65, "Positive keyword further away"},
+		{"call us about anything at all, 2024561414", 0.4, 0.4, "Positive keyword outside the window"},
+		{"Order 2024561414", 0.4, 0.16, "Negative keyword beside the match"},
+		{"Phone order 2024561414", 0.4, 0.27, "Both keywords"},
+	}
+
+	for _, tt := range testCases {
+		start := strings.Index(tt.line, "2024561414")
+		got := scoreContext(tt.confidence, rules, tt.line, start, start+10)
+		if got != tt.want {
+			t.Errorf("scoreContext(%.2f, %q) = %.2f; want %.2f (%s)", tt.confidence, tt.line, got, tt.want, tt.comment)
+		}
+	}
+}
+
+func TestCheckStringsScoresContext(t *testing.T) {
+	testCases := []struct {
+		line          string
+		detectionType string
+		confidence    float64
+		comment       string
+	}{
+		{"2024561414", "phone", 0.4, "Bare digits"},
+		{"Tel 2024561414", "phone", 0.76, "Phone keyword"},
+		{"Invoice ref 2024561414", "phone", 0.16, "Reference number"},
+		{"4111 1111 1111 1111 is the Visa test card", "credit_card", 0.51, "Issuer test number"},
+		{"Reply to jane.doe@gmail.com", "email", 0.9, "Email address"},
+		{"For example jane.doe@gmail.com", "email", 0.36, "Sample email address"},
+		{"Client IP 8.8.4.4", "ip_address", 0.92, "IP keyword"},
+		{"Upgraded to version 2.0.0.1", "ip_address", 0.32, "Version number"},
+		{"Home address: 42 Elm Street", "address", 0.84, "Address keyword"},
+		{"Company headquarters, 42 Elm Street", "address", 0.24, "Business address"},
+		{"NPI 1234567893", "npi", 0.9, "Labelled NPI"},
+		{"Part 1234567893", "npi", 0.2, "Part number"},
+	}
+
+	for _, tt := range testCases {
+		detections, err := checkStrings(tt.line, 1)
+		if err != nil {
+			t.Fatalf("checkStrings(%q) returned error: %v", tt.line, err)
+		}
+		var confidences []float64
+		for _, d := range detections {
+			if d.Type == tt.detectionType {
+				confidences = append(confidences, d.Confidence)
+			}
+		}
+		if len(confidences) != 1 || confidences[0] != tt.confidence {
+			t.Errorf("checkStrings(%q) %s confidences %v; want [%.2f] (%s)", tt.line, tt.detectionType, confidences, tt.confidence, tt.comment)
+		}
+	}
+}
+
+func TestDetectorsDeclareContextRules(t *testing.T) {
+	for _, d := range append(Detectors(), nationalIDDetector{}) {
+		if _, ok := d.(ContextDetector); !ok && d.Type() != "keyword" {
+			t.Errorf("%s detector has no ContextRules", d.Type())
+		}
+	}
+}
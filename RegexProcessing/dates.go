@@ -0,0 +1,233 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"goScan/ReadFunctions"
+)
+
+const monthPattern = `(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)\.?`
+
+var (
+	// isoDateRegex matches year-first dates such as 1961-03-03, optionally followed by
+	// an ISO 8601 time, which is left out of the detection.
+	isoDateRegex = regexp.MustCompile(`\b(\d{4})([-/.])(\d{1,2})([-/.])(\d{1,2})(?:T\d{1,2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?\b`)
+	// numericDateRegex matches day and month first dates: 3/3/1961, 03-03-61, 03.03.1961.
+	numericDateRegex = regexp.MustCompile(`\b(\d{1,2})([-/.])(\d{1,2})([-/.])(\d{4}|\d{2})\b`)
+	// monthFirstRegex matches written dates such as "March 3, 1961" and "Mar 3rd 1961".
+	monthFirstRegex = regexp.MustCompile(`(?i)\b` + monthPattern + `\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+	// dayFirstRegex matches written dates such as "3 March 1961" and "3rd of March, 1961".
+	dayFirstRegex = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?` + monthPattern + `,?\s+(\d{4})\b`)
+
+	// dobKeywordRegex finds the words that label a date of birth.
+	dobKeywordRegex = regexp.MustCompile(`(?i)\b(dob|d\.o\.b|born|birth|birthday|birthdate|date\s+of\s+birth|b-?day)\b`)
+	// dateNegativeRegex finds the words that label dates of events rather than births.
+	dateNegativeRegex = regexp.MustCompile(`(?i)\b(invoice|order|ship(?:ped)?|deliver(?:ed|y)|created|modified|updated|issued|expires?|expiry|due|effective|printed|posted|signed)\b`)
+)
+
+// dobKeywordWindow is how far before and after a date dobKeywordRegex is looked for.
+const dobKeywordWindow = 40
+
+// maxAge bounds the plausible birth range: a date of birth is no more than this many
+// years ago, and not in the future.
+const maxAge = 120
+
+// minUnlabelledAge is how many years ago a date without a DOB keyword or field must
+// be to count as a possible birth date. Recent dates are far more often invoices,
+// log entries and timestamps than the birthdays of infants.
+const minUnlabelledAge = 1
+
+// now is the clock used for the plausible birth range, replaced in tests.
+var now = time.Now
+
+// dateDetector reports dates that could be dates of birth. ISO 8601, numeric dates
+// with single or double digit days and months, and written months are parsed and
+// checked against the calendar, so 02/31/2000 is rejected. A date labelled by a DOB
+// keyword, or stored under a DOB column or key, is reported with keywordConfidence;
+// any other date in the plausible birth range and at least minUnlabelledAge years
+// old is reported with confidence, and other dates are ignored.
+type dateDetector struct {
+	confidence        float64
+	keywordConfidence float64
+}
+
+func (d dateDetector) Type() string { return "dob" }
+
+func (d dateDetector) ContextRules() ContextRules {
+	return ContextRules{Negative: dateNegativeRegex, Window: dobKeywordWindow}
+}
+
+func (d dateDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	return d.detect(line, false)
+}
+
+// DetectField treats a column or key named for dates of birth as the keyword.
+func (d dateDetector) DetectField(field, value string) []ReadFunctions.PIIDetection {
+	if !fieldNamesType(field, d.Type()) {
+		return nil
+	}
+	return d.detect(value, true)
+}
+
+func (d dateDetector) detect(line string, labelled bool) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+	var covered [][2]int
+
+	overlaps := func(start, end int) bool {
+		for _, c := range covered {
+			if start < c[1] && c[0] < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, pattern := range []struct {
+		re    *regexp.Regexp
+		parse func(m []string) (time.Time, bool)
+	}{
+		{isoDateRegex, parseISODate},
+		{monthFirstRegex, parseMonthFirst},
+		{dayFirstRegex, parseDayFirst},
+		{numericDateRegex, parseNumericDate},
+	} {
+		for _, loc := range pattern.re.FindAllStringSubmatchIndex(line, -1) {
+			m := make([]string, len(loc)/2)
+			for i := range m {
+				if loc[2*i] >= 0 {
+					m[i] = line[loc[2*i]:loc[2*i+1]]
+				}
+			}
+
+			start, end := loc[0], loc[1]
+			if pattern.re == isoDateRegex {
+				end = loc[11] // the day, leaving out any time
+			}
+			if overlaps(start, end) {
+				continue
+			}
+
+			keyword := labelled || keywordNear(dobKeywordRegex, line, start, end, dobKeywordWindow)
+			date, ok := pattern.parse(m)
+			if !ok || !plausibleBirthDate(date, keyword) {
+				continue
+			}
+			covered = append(covered, [2]int{start, end})
+
+			confidence := d.confidence
+			if keyword {
+				confidence = d.keywordConfidence
+			}
+
+			match := line[start:end]
+			detections = append(detections, ReadFunctions.PIIDetection{
+				Type:          d.Type(),
+				Value:         match,
+				RedactedValue: redactAll(match),
+				StartOffset:   start,
+				EndOffset:     end,
+				Confidence:    confidence,
+			})
+		}
+	}
+
+	return detections
+}
+
+// plausibleBirthDate reports whether date is no more than maxAge years ago and no
+// later than today, or than minUnlabelledAge years ago for a date with no label.
+func plausibleBirthDate(date time.Time, labelled bool) bool {
+	latest := now()
+	if !labelled {
+		latest = latest.AddDate(-minUnlabelledAge, 0, 0)
+	}
+	return !date.After(latest) && date.After(now().AddDate(-maxAge, 0, 0))
+}
+
+// calendarDate returns the date if year, month and day name a real calendar day.
+func calendarDate(year, month, day int) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 {
+		return time.Time{}, false
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day { // time.Date normalises 31 February to March
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// parseISODate handles isoDateRegex: year, separator, month, separator, day.
+func parseISODate(m []string) (time.Time, bool) {
+	if m[2] != m[4] {
+		return time.Time{}, false
+	}
+	return calendarDate(atoi(m[1]), atoi(m[3]), atoi(m[5]))
+}
+
+// parseNumericDate handles numericDateRegex. Dotted dates are day first, as in
+// most of Europe, and need a four digit year so version numbers aren't taken for
+// dates. Slash and dash dates are read month first, as in the US, falling back to
+// day first when that is the only valid reading (31/12/1980).
+func parseNumericDate(m []string) (time.Time, bool) {
+	sep, year := m[2], m[5]
+	if sep != m[4] {
+		return time.Time{}, false
+	}
+	first, second := atoi(m[1]), atoi(m[3])
+
+	if sep == "." {
+		if len(year) != 4 {
+			return time.Time{}, false
+		}
+		return calendarDate(atoi(year), second, first)
+	}
+
+	y := expandYear(year)
+	if date, ok := calendarDate(y, first, second); ok {
+		return date, true
+	}
+	return calendarDate(y, second, first)
+}
+
+// parseMonthFirst handles monthFirstRegex: month name, day, year.
+func parseMonthFirst(m []string) (time.Time, bool) {
+	return calendarDate(atoi(m[3]), monthNumber(m[1]), atoi(m[2]))
+}
+
+// parseDayFirst handles dayFirstRegex: day, month name, year.
+func parseDayFirst(m []string) (time.Time, bool) {
+	return calendarDate(atoi(m[3]), monthNumber(m[2]), atoi(m[1]))
+}
+
+// expandYear turns a two digit year into the most recent matching year that isn't
+// in the future, as a date of birth can't be.
+func expandYear(year string) int {
+	y := atoi(year)
+	if len(year) != 2 {
+		return y
+	}
+	century := now().Year() / 100 * 100
+	if century+y > now().Year() {
+		return century - 100 + y
+	}
+	return century + y
+}
+
+// monthNumber returns 1-12 for an English month name or abbreviation.
+func monthNumber(name string) int {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for i, month := range []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"} {
+		if strings.HasPrefix(name, month) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,69 @@
+package RegexProcessing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateDetector(t *testing.T) {
+	defer func(saved func() time.Time) { now = saved }(now)
+	now = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+
+	testCases := []struct {
+		text       string
+		value      string
+		confidence float64
+		comment    string
+	}{
+		{"01/02/1960", "01/02/1960", 0.4, "US numeric date"},
+		{"DOB: 01/02/1960", "01/02/1960", 0.85, "Labelled date of birth"},
+		{"born 1/2/1960", "1/2/1960", 0.85, "Single digit month and day"},
+		{"11-03-90", "11-03-90", 0.4, "Two digit year"},
+		{"31/12/1980", "31/12/1980", 0.4, "Day first when month first is impossible"},
+		{"01.02.2000", "01.02.2000", 0.4, "Dotted day first date"},
+		{"Date of birth 1961-03-03", "1961-03-03", 0.85, "ISO 8601"},
+		{"created 1999-12-31T23:59:59Z", "1999-12-31", 0.4, "ISO 8601 with time"},
+		{"Birth date: March 3, 1961", "March 3, 1961", 0.85, "Written month first"},
+		{"Mar. 3rd 1961", "Mar. 3rd 1961", 0.4, "Abbreviated month with ordinal"},
+		{"3rd of March, 1961", "3rd of March, 1961", 0.4, "Written day first"},
+		{"29/02/2000", "29/02/2000", 0.4, "Leap day"},
+
+		{"02/31/2000", "", 0, "No 31st of February"},
+		{"29/02/1900", "", 0, "1900 was not a leap year"},
+		{"13/13/2000", "", 0, "No thirteenth month either way"},
+		{"DOB: 03/15/2026", "03/15/2026", 0.85, "Labelled birth this year"},
+		{"DOB: 01/01/2030", "", 0, "In the future"},
+		{"Invoice date 2026-03-15", "", 0, "Recent dates need a label"},
+		{"2025-11-01 12:00:01 GET /index.html", "", 0, "Log timestamp from last month"},
+		{"01.01.1850", "", 0, "Too long ago"},
+		{"version 1.2.10", "", 0, "Dotted dates need a four digit year"},
+		{"2000-01/02", "", 0, "Mixed separators"},
+		{"February 30, 1990", "", 0, "Written month, impossible day"},
+	}
+
+	detector := dateDetector{confidence: 0.4, keywordConfidence: 0.85}
+	for _, tt := range testCases {
+		detections := detector.Detect(tt.text)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("Detect(%q) = %q; want no match (%s)", tt.text, detections[0].Value, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("Detect(%q) returned %d detections; want 1 (%s)", tt.text, len(detections), tt.comment)
+			continue
+		}
+		if d := detections[0]; d.Value != tt.value || d.Confidence != tt.confidence {
+			t.Errorf("Detect(%q) = %q confidence %.2f; want %q %.2f (%s)",
+				tt.text, d.Value, d.Confidence, tt.value, tt.confidence, tt.comment)
+		}
+	}
+
+	if detections := detector.DetectField("date_of_birth", "2026-09-01"); len(detections) != 1 || detections[0].Confidence != 0.85 {
+		t.Errorf("DetectField under date_of_birth = %v; want the recent date with keyword confidence", detections)
+	}
+	if detections := detector.DetectField("created_at", "2026-09-01"); len(detections) != 0 {
+		t.Errorf("DetectField under created_at = %v; want no match", detections)
+	}
+}
@@ -0,0 +1,208 @@
+package RegexProcessing
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"goScan/ReadFunctions"
+)
+
+// Detector finds a single kind of sensitive identifier in a line of text.
+type Detector interface {
+	// Type is the PIIDetection.Type reported for matches, e.g. "ssn" or "email".
+	Type() string
+	// Detect returns every match in line. Offsets are relative to the start of line;
+	// checkStrings fills in the line number, context and detection method.
+	Detect(line string) []ReadFunctions.PIIDetection
+}
+
+// FieldDetector is implemented by detectors that can also recognise a whole value
+// by the column header or key it was stored under, such as a medical record number
+// in an "mrn" column, which carries no label of its own. DetectField is only
+// called when Detect found nothing in the value.
+type FieldDetector interface {
+	Detector
+	DetectField(field, value string) []ReadFunctions.PIIDetection
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Detector
+)
+
+// Register adds a detector to the registry. Detectors run in registration order and
+// each type may only be registered once.
+func Register(d Detector) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, existing := range registry {
+		if existing.Type() == d.Type() {
+			return fmt.Errorf("detector already registered for type %q", d.Type())
+		}
+	}
+	registry = append(registry, d)
+	return nil
+}
+
+// Unregister removes the detector for the given type, reporting whether one was found.
+func Unregister(detectionType string) bool {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i, d := range registry {
+		if d.Type() == detectionType {
+			registry = append(registry[:i:i], registry[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Detectors returns a snapshot of the registered detectors in registration order.
+func Detectors() []Detector {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]Detector(nil), registry...)
+}
+
+// mustRegister is used for the built-in detectors, where a duplicate is a programming error.
+func mustRegister(d Detector) {
+	if err := Register(d); err != nil {
+		panic(err)
+	}
+}
+
+// keywordWindow is how far before and after a match regexDetector and codeDetector
+// look for their keywords.
+const keywordWindow = 40
+
+// regexDetector is a Detector backed by a single regular expression, with an optional
+// validator to discard matches the pattern alone can't rule out. When keyword matches
+// near a match, it is reported with keywordConfidence instead of confidence; when
+// negative does, context scoring lowers it.
+type regexDetector struct {
+	detectionType     string
+	pattern           *regexp.Regexp
+	confidence        float64
+	validate          func(match string) bool
+	redact            func(match string) string
+	keyword           *regexp.Regexp
+	keywordConfidence float64
+	negative          *regexp.Regexp
+}
+
+func (r regexDetector) Type() string { return r.detectionType }
+
+func (r regexDetector) ContextRules() ContextRules {
+	return ContextRules{Negative: r.negative, Window: keywordWindow}
+}
+
+func (r regexDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+
+	for _, loc := range r.pattern.FindAllStringIndex(line, -1) {
+		match := line[loc[0]:loc[1]]
+		if r.validate != nil && !r.validate(match) {
+			continue
+		}
+
+		redact := r.redact
+		if redact == nil {
+			redact = redactAll
+		}
+
+		confidence := r.confidence
+		if r.keyword != nil && keywordNear(r.keyword, line, loc[0], loc[1], keywordWindow) {
+			confidence = r.keywordConfidence
+		}
+
+		detections = append(detections, ReadFunctions.PIIDetection{
+			Type:          r.detectionType,
+			Value:         match,
+			RedactedValue: redact(match),
+			StartOffset:   loc[0],
+			EndOffset:     loc[1],
+			Confidence:    confidence,
+		})
+	}
+
+	return detections
+}
+
+// codeDetector reports codes from a vocabulary or numbering scheme, such as
+// diagnosis codes or bank routing numbers. Such codes are short and easily confused
+// with part numbers, ZIP codes and ranges, so matches the weak function accepts are
+// only reported with a keyword nearby or under a column or key that names the
+// scheme; other matches are reported with confidence, raised to keywordConfidence
+// by a keyword. Context scoring lowers matches near negative.
+type codeDetector struct {
+	detectionType     string
+	pattern           *regexp.Regexp
+	keyword           *regexp.Regexp
+	negative          *regexp.Regexp
+	validate          func(match string) bool
+	weak              func(match string) bool
+	redact            func(match string) string
+	confidence        float64
+	keywordConfidence float64
+}
+
+func (c codeDetector) Type() string { return c.detectionType }
+
+func (c codeDetector) ContextRules() ContextRules {
+	return ContextRules{Negative: c.negative, Window: keywordWindow}
+}
+
+func (c codeDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	return c.detect(line, false)
+}
+
+// DetectField treats a column or key named for the scheme as the keyword.
+func (c codeDetector) DetectField(field, value string) []ReadFunctions.PIIDetection {
+	if !fieldNamesType(field, c.detectionType) {
+		return nil
+	}
+	return c.detect(value, true)
+}
+
+func (c codeDetector) detect(line string, labelled bool) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+
+	for _, loc := range c.pattern.FindAllStringIndex(line, -1) {
+		match := line[loc[0]:loc[1]]
+		if c.validate != nil && !c.validate(match) {
+			continue
+		}
+
+		keyword := labelled || keywordNear(c.keyword, line, loc[0], loc[1], keywordWindow)
+		confidence := c.confidence
+		switch {
+		case keyword:
+			confidence = c.keywordConfidence
+		case c.weak != nil && c.weak(match):
+			continue
+		}
+
+		redact := c.redact
+		if redact == nil {
+			redact = redactAll
+		}
+
+		detections = append(detections, ReadFunctions.PIIDetection{
+			Type:          c.detectionType,
+			Value:         match,
+			RedactedValue: redact(match),
+			StartOffset:   loc[0],
+			EndOffset:     loc[1],
+			Confidence:    confidence,
+		})
+	}
+
+	return detections
+}
+
+// always marks every match as weak, for schemes only reported with a keyword.
+func always(string) bool { return true }
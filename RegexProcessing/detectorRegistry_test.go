@@ -0,0 +1,82 @@
+package RegexProcessing
+
+import (
+	"testing"
+
+	"goScan/ReadFunctions"
+)
+
+func TestCheckStrings(t *testing.T) {
+	line := "Contact john@example.com, SSN 123-45-6789"
+
+	detections, err := checkStrings(line, 7)
+	if err != nil {
+		t.Fatalf("checkStrings(%q) returned error: %v", line, err)
+	}
+
+	want := map[string]string{
+		"email": "john@example.com",
+		"ssn":   "123-45-6789",
+	}
+	found := map[string]ReadFunctions.PIIDetection{}
+	for _, d := range detections {
+		found[d.Type] = d
+	}
+
+	for typ, value := range want {
+		d, ok := found[typ]
+		if !ok {
+			t.Errorf("checkStrings(%q) missing %s detection", line, typ)
+			continue
+		}
+		if d.Value != value {
+			t.Errorf("%s Value = %q; want %q", typ, d.Value, value)
+		}
+		if line[d.StartOffset:d.EndOffset] != value {
+			t.Errorf("%s offsets [%d:%d] = %q; want %q", typ, d.StartOffset, d.EndOffset, line[d.StartOffset:d.EndOffset], value)
+		}
+		if d.LineNumber != 7 {
+			t.Errorf("%s LineNumber = %d; want 7", typ, d.LineNumber)
+		}
+		if d.DetectionMethod != "regex" {
+			t.Errorf("%s DetectionMethod = %q; want %q", typ, d.DetectionMethod, "regex")
+		}
+		if d.Context == "" {
+			t.Errorf("%s Context is empty", typ)
+		}
+	}
+
+	if got := found["ssn"].RedactedValue; got != "XXX-XX-6789" {
+		t.Errorf("ssn RedactedValue = %q; want %q", got, "XXX-XX-6789")
+	}
+
+	for i := 1; i < len(detections); i++ {
+		if detections[i].StartOffset < detections[i-1].StartOffset {
+			t.Errorf("detections not ordered by StartOffset: %d before %d", detections[i-1].StartOffset, detections[i].StartOffset)
+		}
+	}
+}
+
+func TestScanSegmentOffsets(t *testing.T) {
+	seg := ReadFunctions.Segment{Text: "mail admin@company.org", LineNumber: 3, Offset: 100}
+
+	detections, err := ScanSegment(seg)
+	if err != nil {
+		t.Fatalf("ScanSegment returned error: %v", err)
+	}
+	for _, d := range detections {
+		if d.Type == "email" {
+			if d.StartOffset != 105 || d.EndOffset != 122 {
+				t.Errorf("email offsets = [%d:%d]; want [105:122]", d.StartOffset, d.EndOffset)
+			}
+			return
+		}
+	}
+	t.Errorf("ScanSegment(%q) found no email", seg.Text)
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	if err := Register(regexDetector{detectionType: "email", pattern: emailRegex}); err == nil {
+		t.Errorf("Register accepted a second detector for type %q", "email")
+	}
+}
@@ -0,0 +1,111 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"goScan/ReadFunctions"
+)
+
+// documentKeywords are the words that suggest what kind of document a file is, by
+// document type, with how much each one counts. Words that are hard to find outside
+// that kind of document weigh 0.4, so two of them are enough to classify a file;
+// everyday words that lean one way weigh 0.2.
+var documentKeywords = map[string]map[string]float64{
+	"medical": {
+		"patient": 0.4, "diagnosis": 0.4, "diagnosed": 0.4, "physician": 0.4, "prescription": 0.4,
+		"medical record": 0.4, "discharge summary": 0.4, "chief complaint": 0.4, "allergies": 0.4,
+		"dosage": 0.4, "prognosis": 0.4, "hipaa": 0.4, "vital signs": 0.4, "lab results": 0.4,
+		"clinic": 0.2, "hospital": 0.2, "medication": 0.2, "treatment": 0.2, "symptoms": 0.2,
+		"admission": 0.2, "nurse": 0.2,
+	},
+	"financial": {
+		"account balance": 0.4, "wire transfer": 0.4, "routing number": 0.4, "account number": 0.4,
+		"statement period": 0.4, "interest rate": 0.4, "dividend": 0.4, "tax return": 0.4, "1099": 0.4,
+		"invoice": 0.2, "payment": 0.2, "transaction": 0.2, "deposit": 0.2, "withdrawal": 0.2,
+		"loan": 0.2, "balance": 0.2, "bank": 0.2, "portfolio": 0.2,
+	},
+	"legal": {
+		"plaintiff": 0.4, "defendant": 0.4, "petitioner": 0.4, "respondent": 0.4, "subpoena": 0.4,
+		"affidavit": 0.4, "deposition": 0.4, "litigation": 0.4, "jurisdiction": 0.4, "indemnify": 0.4,
+		"hereinafter": 0.4, "whereas": 0.4, "pursuant to": 0.4, "court": 0.2, "attorney": 0.2,
+		"counsel": 0.2, "agreement": 0.2, "contract": 0.2, "settlement": 0.2, "notary": 0.2, "hereby": 0.2,
+	},
+	"hr": {
+		"payroll": 0.4, "performance review": 0.4, "offer letter": 0.4, "onboarding": 0.4, "hire date": 0.4,
+		"date of hire": 0.4, "termination date": 0.4, "background check": 0.4, "pto": 0.4, "w-4": 0.4,
+		"i-9": 0.4, "w-2": 0.4, "401(k)": 0.4, "employee id": 0.4,
+		"employee": 0.2, "salary": 0.2, "compensation": 0.2, "benefits": 0.2, "job title": 0.2,
+		"department": 0.2, "manager": 0.2, "resignation": 0.2,
+	},
+}
+
+// caseNumberRegex matches court case numbers: labelled ones such as "Case No. 2023-CV-1234"
+// and federal ones such as "1:23-cv-04567".
+var caseNumberRegex = regexp.MustCompile(`(?i)\b(?:case|docket|civil\s+action|cause)\s*(?:no\.?|number|#)\s*[:#]?\s*[A-Z0-9]*\d[A-Z0-9:-]*|` +
+	`\b\d:\d{2}-(?:cv|cr|bk|mc|md|mj)-\d{3,5}\b`)
+
+// caseNumberWeight is how much a case number counts towards "legal".
+const caseNumberWeight = 0.5
+
+// documentKeywordRegexes matches each document type's keywords, longest first so a
+// phrase wins over a word it starts with, such as "account balance" over "balance".
+var documentKeywordRegexes = func() map[string]*regexp.Regexp {
+	res := map[string]*regexp.Regexp{}
+	for docType, words := range documentKeywords {
+		var alternatives []string
+		for word := range words {
+			alternative := strings.ReplaceAll(regexp.QuoteMeta(word), " ", `\s+`)
+			if isAlnum(word[len(word)-1]) {
+				alternative += `\b`
+			}
+			alternatives = append(alternatives, alternative)
+		}
+		sort.Slice(alternatives, func(i, j int) bool {
+			if len(alternatives[i]) != len(alternatives[j]) {
+				return len(alternatives[i]) > len(alternatives[j])
+			}
+			return alternatives[i] < alternatives[j]
+		})
+		res[docType] = regexp.MustCompile(`(?i)\b(?:` + strings.Join(alternatives, "|") + `)`)
+	}
+	return res
+}()
+
+// keywordDetector reports the documentKeywords and case numbers in a line as
+// "keyword" detections, with the document type as the Subtype, the keyword in lower
+// case as the Value and its weight as the Confidence. They aren't findings:
+// ReadFunctions uses them to classify the file and leaves them out of the results.
+type keywordDetector struct{}
+
+func (k keywordDetector) Type() string { return "keyword" }
+
+func (k keywordDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+
+	for docType, re := range documentKeywordRegexes {
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			word := strings.Join(strings.Fields(strings.ToLower(line[loc[0]:loc[1]])), " ")
+			detections = append(detections, k.detection(docType, word, loc[0], loc[1], documentKeywords[docType][word]))
+		}
+	}
+
+	for _, loc := range caseNumberRegex.FindAllStringIndex(line, -1) {
+		detections = append(detections, k.detection("legal", "case number", loc[0], loc[1], caseNumberWeight))
+	}
+
+	return detections
+}
+
+func (k keywordDetector) detection(docType, word string, start, end int, weight float64) ReadFunctions.PIIDetection {
+	return ReadFunctions.PIIDetection{
+		Type:          k.Type(),
+		Subtype:       docType,
+		Value:         word,
+		RedactedValue: word,
+		StartOffset:   start,
+		EndOffset:     end,
+		Confidence:    weight,
+	}
+}
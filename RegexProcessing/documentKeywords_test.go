@@ -0,0 +1,41 @@
+package RegexProcessing
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestKeywordDetector(t *testing.T) {
+	testCases := []struct {
+		text    string
+		want    []string // document type and keyword of each detection, in order
+		comment string
+	}{
+		{"The Plaintiff, by counsel, moves to dismiss", []string{"legal plaintiff", "legal counsel"}, "Legal keywords"},
+		{"Case No. 2023-CV-01234", []string{"legal case number"}, "Labelled case number"},
+		{"Smith v. Jones, 1:23-cv-04567 (S.D.N.Y.)", []string{"legal case number"}, "Federal case number"},
+		{"Patient diagnosed with hypertension", []string{"medical patient", "medical diagnosed"}, "Medical keywords"},
+		{"Account  Balance: $1,200", []string{"financial account balance"}, "Phrase wins over the word inside it"},
+		{"Enrol in the 401(k) before your hire date", []string{"hr 401(k)", "hr hire date"}, "HR keywords"},
+		{"Impatient employee", []string{"hr employee"}, "Whole words only"},
+		{"nothing to see here", nil, "No keywords"},
+	}
+
+	d := keywordDetector{}
+	for _, tt := range testCases {
+		var got []string
+		detections := d.Detect(tt.text)
+		sort.Slice(detections, func(i, j int) bool { return detections[i].StartOffset < detections[j].StartOffset })
+		for _, detection := range detections {
+			got = append(got, detection.Subtype+" "+detection.Value)
+			if detection.Confidence != documentKeywords[detection.Subtype][detection.Value] &&
+				detection.Value != "case number" {
+				t.Errorf("Detect(%q) %q Confidence = %.2f; want its weight (%s)", tt.text, detection.Value, detection.Confidence, tt.comment)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Detect(%q) = %q; want %q (%s)", tt.text, got, tt.want, tt.comment)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package RegexProcessing
+
+import (
+	"strings"
+	"unicode"
+
+	"goScan/ReadFunctions"
+)
+
+// fieldBoost is the share of the remaining doubt removed from a detection's
+// Confidence when the column header or key it was found under names its type.
+const fieldBoost = 0.6
+
+// fieldKeywords maps detection types to the words that name them in column headers
+// and keys. Single words must match a whole token of the field name; compound
+// entries are matched against the name with separators removed.
+var fieldKeywords = map[string][]string{
+	"ssn":   {"ssn", "social", "socialsecurity", "socsec", "taxpayer", "tin"},
+	"email": {"email", "mail", "emailaddress"},
+	"phone": {"phone", "tel", "telephone", "mobile", "cell", "fax", "phonenumber"},
+	"dob":   {"dob", "birth", "birthday", "birthdate", "dateofbirth", "born"},
+	"credit_card": {"card", "cc", "pan", "ccnum", "ccnumber", "cardnumber", "creditcard",
+		"visa", "mastercard", "amex"},
+	"name": {"name", "fname", "lname", "firstname", "lastname", "fullname", "surname",
+		"givenname", "patientname", "patient", "employee", "customer", "contact"},
+	"address": {"address", "addr", "street", "city", "zip", "zipcode", "postal", "postcode",
+		"streetaddress", "mailingaddress", "homeaddress"},
+	"npi": {"npi", "provider", "providerid", "prescriber"},
+	"dea": {"dea", "deanumber", "prescriber"},
+	"mbi": {"mbi", "medicare", "medicareid", "medicarenumber"},
+	"mrn": {"mrn", "chart", "medicalrecord", "medicalrecordnumber", "medrec", "patientid"},
+	"health_plan": {"member", "memberid", "subscriber", "subscriberid", "beneficiary", "policy",
+		"policynumber", "insurance", "insuranceid", "healthplan", "medicaid", "groupnumber"},
+	"icd10":        {"icd", "icd10", "icd10cm", "diagnosis", "dx", "diag", "diagnosiscode"},
+	"cpt":          {"cpt", "hcpcs", "procedure", "proc", "px", "procedurecode"},
+	"ndc":          {"ndc", "drug", "drugcode", "ndccode", "medication"},
+	"loinc":        {"loinc", "lab", "labcode", "loinccode", "test", "testcode"},
+	"iban":         {"iban", "ibannumber"},
+	"aba_routing":  {"routing", "aba", "rtn", "routingnumber", "transit"},
+	"swift_bic":    {"swift", "bic", "swiftcode", "biccode", "swiftbic"},
+	"ein":          {"ein", "fein", "employerid", "taxid", "federaltaxid"},
+	"bank_account": {"account", "acct", "accountnumber", "accountno", "bankaccount", "acctno"},
+	"ip_address":   {"ip", "ipaddress", "ipaddr", "ipv4", "ipv6", "clientip", "remoteaddr", "remoteip", "sourceip"},
+	"mac_address":  {"mac", "macaddress", "macaddr", "hwaddr", "hwaddress"},
+	"imei":         {"imei", "deviceid", "handset"},
+	"vin":          {"vin", "vehicleid", "chassis"},
+	"url":          {"url", "website", "homepage", "profile", "link", "social"},
+	"secret": {"secret", "token", "password", "passwd", "pwd", "apikey", "credential", "credentials",
+		"privatekey", "accesskey", "secretkey", "clientsecret", "auth"},
+
+	// National ID schemes are keyed by subtype, so a "sin" column labels only Canadian SINs.
+	"national_id":       {"nationalid", "nationalidnumber"},
+	"uk_nino":           {"nino", "nationalinsurance"},
+	"uk_nhs":            {"nhs", "nhsnumber"},
+	"it_codice_fiscale": {"codicefiscale"},
+	"es_dni":            {"dni", "nif"},
+	"es_nie":            {"nie", "nif"},
+	"in_pan":            {"pan"},
+	"in_aadhaar":        {"aadhaar", "aadhar"},
+	"fr_insee":          {"insee", "nir"},
+	"br_cpf":            {"cpf"},
+	"de_steuer_id":      {"steuerid", "idnr"},
+	"nl_bsn":            {"bsn"},
+	"ca_sin":            {"sin", "socialinsurance"},
+}
+
+// fieldNames splits a header or key such as "patient_name" or "dateOfBirth" into
+// lower case tokens, and also returns the tokens joined without separators.
+func fieldNames(field string) ([]string, string) {
+	var tokens []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	prev := rune(0)
+	for _, r := range field {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+		prev = r
+	}
+	flush()
+	return tokens, strings.Join(tokens, "")
+}
+
+// fieldNamesType reports whether field, a column header or key, names detectionType.
+// For a dotted key path such as "patient.dob" only the last key counts.
+func fieldNamesType(field, detectionType string) bool {
+	if i := strings.LastIndexByte(field, '.'); i >= 0 {
+		field = field[i+1:]
+	}
+	if field == "" {
+		return false
+	}
+	tokens, joined := fieldNames(field)
+	for _, keyword := range fieldKeywords[detectionType] {
+		if joined == keyword {
+			return true
+		}
+		for _, token := range tokens {
+			if token == keyword {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyFieldContext raises the Confidence of detections whose type, or subtype, is
+// named by the header or key they were found under, and records the field in their Context.
+func applyFieldContext(detections []ReadFunctions.PIIDetection, field string) {
+	if field == "" {
+		return
+	}
+	for i := range detections {
+		detections[i].Context = field + ": " + detections[i].Context
+		d := detections[i]
+		if fieldNamesType(field, d.Type) || d.Subtype != "" && fieldNamesType(field, d.Subtype) {
+			detections[i].Confidence += (1 - detections[i].Confidence) * fieldBoost
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package RegexProcessing
+
+import (
+	"testing"
+
+	"goScan/ReadFunctions"
+)
+
+func TestFieldNamesType(t *testing.T) {
+	testCases := []struct {
+		field         string
+		detectionType string
+		expected      bool
+	}{
+		{"ssn", "ssn", true},
+		{"Patient_SSN", "ssn", true},
+		{"patient_name", "name", true},
+		{"dateOfBirth", "dob", true},
+		{"DOB", "dob", true},
+		{"emailAddress", "email", true},
+		{"cell-phone", "phone", true},
+		{"cancellation", "phone", false}, // "cell" only counts as a whole token
+		{"notes", "ssn", false},
+		{"patient.dob", "dob", true},
+		{"patient.dob", "name", false}, // only the last key of a path counts
+		{"", "email", false},
+	}
+
+	for _, tt := range testCases {
+		if got := fieldNamesType(tt.field, tt.detectionType); got != tt.expected {
+			t.Errorf("fieldNamesType(%q, %q) = %v; want %v", tt.field, tt.detectionType, got, tt.expected)
+		}
+	}
+}
+
+func TestScanSegmentField(t *testing.T) {
+	plain, err := ScanSegment(ReadFunctions.Segment{Text: "123-45-6789"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	boosted, err := ScanSegment(ReadFunctions.Segment{Text: "123-45-6789", Field: "patient_ssn", Location: "row 2 c3 (patient_ssn)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confidence := func(detections []ReadFunctions.PIIDetection) (float64, string) {
+		for _, d := range detections {
+			if d.Type == "ssn" {
+				return d.Confidence, d.Context
+			}
+		}
+		return 0, ""
+	}
+	before, _ := confidence(plain)
+	after, context := confidence(boosted)
+	if after <= before {
+		t.Errorf("ssn Confidence under an ssn header = %.2f; want more than %.2f", after, before)
+	}
+	if context != "patient_ssn: 123-45-6789" {
+		t.Errorf("ssn Context = %q; want the header name included", context)
+	}
+}
@@ -0,0 +1,124 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// ibanRegex matches IBANs: a country code, two check digits and up to 30 letters
+	// and digits, printed together or in groups of four.
+	ibanRegex        = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`)
+	ibanKeywordRegex = regexp.MustCompile(`(?i)\biban\b`)
+
+	// routingRegex matches nine digit ABA routing transit numbers.
+	routingRegex        = regexp.MustCompile(`\b\d{9}\b`)
+	routingKeywordRegex = regexp.MustCompile(`(?i)\b(routing|aba|rtn|transit)\b`)
+
+	// bicRegex matches SWIFT/BIC codes: a four letter bank code, a country code, a two
+	// character location and an optional three character branch.
+	bicRegex        = regexp.MustCompile(`\b[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}(?:[A-Z0-9]{3})?\b`)
+	bicKeywordRegex = regexp.MustCompile(`(?i)\b(swift|bic|bank|beneficiary\s+bank|correspondent)\b`)
+
+	// einRegex matches US Employer Identification Numbers, 12-3456789.
+	einRegex        = regexp.MustCompile(`\b\d{2}-?\d{7}\b`)
+	einKeywordRegex = regexp.MustCompile(`(?i)\b(ein|fein|employer\s+id(?:entification)?|federal\s+tax\s+id|tax\s*id|tin)\b`)
+
+	// accountLabelRegex matches a bank account number label and the number after it.
+	accountLabelRegex = regexp.MustCompile(`(?i)\b(?:bank\s+)?(?:account|acct|a/c)\s*(?:number|num|no\.?|#)?\s*[:#=]?\s*` +
+		`(\d[\d-]{4,15}\d)\b`)
+)
+
+// ibanLengths is the IBAN length for each country in the SWIFT IBAN registry.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BI": 27,
+	"BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28,
+	"EE": 20, "EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23,
+	"GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27,
+	"JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "LY": 25,
+	"MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27, "MT": 31, "MU": 30, "NI": 28, "NL": 18,
+	"NO": 15, "OM": 23, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33,
+	"SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
+
+// countryCodes is the set of ISO 3166-1 alpha-2 codes, plus XK, which SWIFT uses for Kosovo.
+var countryCodes = func() map[string]bool {
+	codes := map[string]bool{}
+	for _, code := range strings.Fields(`
+		AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS
+		BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE
+		EG EH ER ES ET FI FJ FK FM FO FR GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM
+		HN HR HT HU ID IE IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC
+		LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ NA
+		NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR PS PT PW PY QA RE RO RS RU RW
+		SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO
+		TR TT TV TW TZ UA UG UM US UY UZ VA VC VE VG VI VN VU WF WS XK YE YT ZA ZM ZW`) {
+		codes[code] = true
+	}
+	return codes
+}()
+
+// einPrefixes are the first two digits the IRS assigns EINs under.
+var einPrefixes = []zipRange{
+	{1, 6}, {10, 16}, {20, 27}, {30, 48}, {50, 68}, {71, 77}, {80, 88}, {90, 95}, {98, 99},
+}
+
+// ibanValid checks an IBAN's length for its country and its ISO 7064 mod 97 check
+// digits: with the first four characters moved to the end and letters replaced by
+// 10-35, the number leaves a remainder of 1.
+func ibanValid(match string) bool {
+	iban := strings.ReplaceAll(match, " ", "")
+	if ibanLengths[iban[:2]] != len(iban) {
+		return false
+	}
+
+	remainder := 0
+	for _, c := range iban[4:] + iban[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
+
+// routingValid checks an ABA routing number's Federal Reserve prefix and its
+// checksum: 3, 7 and 1 times the digits in turn sum to a multiple of 10.
+func routingValid(number string) bool {
+	prefix := atoi(number[:2])
+	if !(prefix <= 12 || prefix >= 21 && prefix <= 32 || prefix >= 61 && prefix <= 72 || prefix == 80) {
+		return false
+	}
+
+	sum := 0
+	for i, weight := range []int{3, 7, 1, 3, 7, 1, 3, 7, 1} {
+		sum += weight * int(number[i]-'0')
+	}
+	return sum%10 == 0
+}
+
+// bicValid checks a BIC's country code.
+func bicValid(bic string) bool {
+	return countryCodes[bic[4:6]]
+}
+
+// einValid checks an EIN's prefix against those the IRS assigns.
+func einValid(ein string) bool {
+	prefix := atoi(ein[:2])
+	for _, r := range einPrefixes {
+		if prefix >= r.from && prefix <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+// einWeak reports EINs written without the dash, which look like any nine digits.
+func einWeak(ein string) bool {
+	return !strings.Contains(ein, "-")
+}
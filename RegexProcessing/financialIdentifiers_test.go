@@ -0,0 +1,65 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestFinancialIdentifiers(t *testing.T) {
+	testCases := []struct {
+		detectionType string
+		text          string
+		value         string // "" when nothing should be found
+		confidence    float64
+		comment       string
+	}{
+		{"iban", "IBAN: DE89 3704 0044 0532 0130 00", "DE89 3704 0044 0532 0130 00", 0.95, "German IBAN in groups"},
+		{"iban", "pay GB82WEST12345698765432", "GB82WEST12345698765432", 0.9, "UK IBAN without spaces"},
+		{"iban", "NO9386011117947", "NO9386011117947", 0.9, "Shortest IBAN"},
+		{"iban", "DE88 3704 0044 0532 0130 00", "", 0, "Bad check digits"},
+		{"iban", "DE89 3704 0044 0532 0130", "", 0, "Too short for Germany"},
+		{"aba_routing", "Routing: 021000021", "021000021", 0.9, "Valid routing number"},
+		{"aba_routing", "ABA 011000015", "011000015", 0.9, "Another valid routing number"},
+		{"aba_routing", "routing 021000022", "", 0, "Checksum fails"},
+		{"aba_routing", "ref 021000021", "", 0, "No keyword"},
+		{"swift_bic", "SWIFT: DEUTDEFF", "DEUTDEFF", 0.85, "Eight character BIC"},
+		{"swift_bic", "BIC CHASUS33XXX", "CHASUS33XXX", 0.85, "Eleven character BIC"},
+		{"swift_bic", "SWIFT: DEUTZZFF", "", 0, "Unknown country"},
+		{"swift_bic", "CONTRACT SIGNED", "", 0, "Capitalised words without keyword"},
+		{"ein", "EIN 12-3456789", "12-3456789", 0.9, "EIN with keyword"},
+		{"ein", "vendor 12-3456789", "12-3456789", 0.5, "Dashed EIN without keyword"},
+		{"ein", "Tax ID 123456789", "123456789", 0.9, "Undashed EIN with keyword"},
+		{"ein", "07-1234567", "", 0, "Prefix never assigned"},
+		{"ein", "123456789", "", 0, "Nine digits without keyword"},
+		{"bank_account", "Account #: 0012345678", "0012345678", 0.75, "Account label"},
+		{"bank_account", "Acct No. 4455-667788", "4455-667788", 0.75, "Abbreviated label with dash"},
+		{"bank_account", "account manager 12", "", 0, "No account number"},
+	}
+
+	for _, tt := range testCases {
+		var detector Detector
+		for _, d := range Detectors() {
+			if d.Type() == tt.detectionType {
+				detector = d
+			}
+		}
+		if detector == nil {
+			t.Fatalf("no %s detector registered", tt.detectionType)
+		}
+
+		detections := detector.Detect(tt.text)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("%s Detect(%q) = %q; want no match (%s)", tt.detectionType, tt.text, detections[0].Value, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("%s Detect(%q) returned %d detections; want 1 (%s)", tt.detectionType, tt.text, len(detections), tt.comment)
+			continue
+		}
+		if d := detections[0]; d.Value != tt.value || d.Confidence != tt.confidence {
+			t.Errorf("%s Detect(%q) = %q confidence %.2f; want %q %.2f (%s)",
+				tt.detectionType, tt.text, d.Value, d.Confidence, tt.value, tt.confidence, tt.comment)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"strings"
+
+	"goScan/ReadFunctions"
+)
+
+const (
+	// mbiLetter and mbiAlnum are the characters CMS allows in a Medicare Beneficiary
+	// Identifier: upper case letters without S, L, O, I, B and Z, and digits.
+	mbiLetter = `[AC-HJKMNP-RT-Y]`
+	mbiAlnum  = `[AC-HJKMNP-RT-Y0-9]`
+
+	// idPattern is the shape of a medical record or health plan number.
+	idPattern = `[A-Za-z0-9][A-Za-z0-9-]{3,19}`
+)
+
+var (
+	// npiRegex matches National Provider Identifiers: ten digits starting with 1 or 2.
+	npiRegex        = regexp.MustCompile(`\b[12]\d{9}\b`)
+	npiKeywordRegex = regexp.MustCompile(`(?i)\b(npi|national\s+provider)\b`)
+
+	// deaRegex matches DEA registration numbers: a registrant type letter, the first
+	// letter of the registrant's surname (or 9 for some business types) and seven digits.
+	deaRegex        = regexp.MustCompile(`\b[ABCDEFGHJKLMPRSTUX][A-Z9]\d{7}\b`)
+	deaKeywordRegex = regexp.MustCompile(`(?i)\bdea\b`)
+
+	// mbiRegex matches Medicare Beneficiary Identifiers by CMS's position rules, with
+	// or without the dashes CMS prints after the 4th and 7th characters.
+	mbiRegex = regexp.MustCompile(`\b[1-9]` + mbiLetter + mbiAlnum + `\d-?` + mbiLetter + mbiAlnum + `\d-?` +
+		mbiLetter + mbiLetter + `\d\d\b`)
+	mbiKeywordRegex = regexp.MustCompile(`(?i)\b(mbi|medicare)\b`)
+
+	// mrnLabelRegex matches a medical record number label and the number after it.
+	mrnLabelRegex = regexp.MustCompile(`(?i)\b(?:mrn|medical\s+record\s*(?:number|num|no\.?|#)?|med\s*rec\s*(?:no\.?|#)?|` +
+		`chart\s*(?:number|no\.?|#)|patient\s*id)\s*[:#=]?\s*(` + idPattern + `)\b`)
+
+	// healthPlanLabelRegex matches a health plan beneficiary number label and the number after it.
+	healthPlanLabelRegex = regexp.MustCompile(`(?i)\b(?:member|subscriber|beneficiary|policy|insurance|insured|` +
+		`health\s*plan|medicaid|group)\s*(?:id|identifier|num(?:ber)?|no\.?|#)\s*[:#=]?\s*(` + idPattern + `)\b`)
+
+	// idValueRegex matches a whole value that could be a medical record or health plan number.
+	idValueRegex = regexp.MustCompile(`^` + idPattern + `$`)
+)
+
+// minIDDigits is how many digits a labelled identifier needs, so "MRN: unknown"
+// isn't reported.
+const minIDDigits = 3
+
+// npiValid checks an NPI's check digit: the Luhn checksum over the number prefixed
+// with 80840, the health industry prefix NPIs are issued under.
+func npiValid(npi string) bool {
+	return luhnValid("80840" + npi)
+}
+
+// deaValid checks a DEA number's check digit: the sum of the 1st, 3rd and 5th digits
+// plus twice the sum of the 2nd, 4th and 6th ends in the 7th.
+func deaValid(dea string) bool {
+	d := dea[2:]
+	odd := int(d[0]-'0') + int(d[2]-'0') + int(d[4]-'0')
+	even := int(d[1]-'0') + int(d[3]-'0') + int(d[5]-'0')
+	return (odd+2*even)%10 == int(d[6]-'0')
+}
+
+// mbiValid rejects MBIs with only one of the two dashes.
+func mbiValid(mbi string) bool {
+	dashes := strings.Count(mbi, "-")
+	return dashes == 0 || dashes == 2
+}
+
+// labelledIDDetector reports identifiers with no checkable format of their own, such
+// as medical record and health plan numbers, when a label introduces them ("MRN:
+// 00123456") or they are stored under a column or key that names them.
+type labelledIDDetector struct {
+	detectionType string
+	label         *regexp.Regexp // the label, with the identifier in group 1
+	confidence    float64
+}
+
+func (l labelledIDDetector) Type() string { return l.detectionType }
+
+func (l labelledIDDetector) ContextRules() ContextRules {
+	return ContextRules{Negative: sampleDataRegex, Window: keywordWindow}
+}
+
+func (l labelledIDDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+
+	for _, loc := range l.label.FindAllStringSubmatchIndex(line, -1) {
+		if d, ok := l.detection(line, loc[2], loc[3]); ok {
+			detections = append(detections, d)
+		}
+	}
+
+	return detections
+}
+
+func (l labelledIDDetector) DetectField(field, value string) []ReadFunctions.PIIDetection {
+	if !fieldNamesType(field, l.detectionType) {
+		return nil
+	}
+	trimmed := strings.TrimSpace(value)
+	if !idValueRegex.MatchString(trimmed) {
+		return nil
+	}
+	start := strings.Index(value, trimmed)
+	if d, ok := l.detection(value, start, start+len(trimmed)); ok {
+		return []ReadFunctions.PIIDetection{d}
+	}
+	return nil
+}
+
+// detection reports line[start:end] if it has enough digits to be an identifier.
+func (l labelledIDDetector) detection(line string, start, end int) (ReadFunctions.PIIDetection, bool) {
+	match := line[start:end]
+	digits := 0
+	for i := 0; i < len(match); i++ {
+		if isDigit(match[i]) {
+			digits++
+		}
+	}
+	if digits < minIDDigits {
+		return ReadFunctions.PIIDetection{}, false
+	}
+
+	return ReadFunctions.PIIDetection{
+		Type:          l.detectionType,
+		Value:         match,
+		RedactedValue: redactKeepLast4(match),
+		StartOffset:   start,
+		EndOffset:     end,
+		Confidence:    l.confidence,
+	}, true
+}
@@ -0,0 +1,96 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestMedicalIdentifiers(t *testing.T) {
+	testCases := []struct {
+		detectionType string
+		text          string
+		value         string // "" when nothing should be found
+		confidence    float64
+		comment       string
+	}{
+		{"npi", "Provider NPI: 1234567893", "1234567893", 0.9, "NPI with keyword"},
+		{"npi", "ref 1234567893", "1234567893", 0.5, "Bare NPI"},
+		{"npi", "NPI 1234567890", "", 0, "NPI fails the 80840 Luhn check"},
+		{"npi", "NPI 3234567893", "", 0, "NPIs start with 1 or 2"},
+		{"dea", "DEA# AB1234563", "AB1234563", 0.95, "DEA with keyword"},
+		{"dea", "prescriber BJ6125341", "BJ6125341", 0.8, "DEA without keyword"},
+		{"dea", "AB1234567", "", 0, "DEA checksum fails"},
+		{"dea", "QB1234563", "", 0, "Unknown registrant type"},
+		{"mbi", "Medicare ID 1EG4-TE5-MK73", "1EG4-TE5-MK73", 0.95, "MBI with dashes and keyword"},
+		{"mbi", "1EG4TE5MK73", "1EG4TE5MK73", 0.85, "MBI without dashes"},
+		{"mbi", "1EG4-TE5MK73", "", 0, "Only one dash"},
+		{"mbi", "1EG4-SE5-MK73", "", 0, "S is never used"},
+		{"mbi", "0EG4-TE5-MK73", "", 0, "First character can't be zero"},
+		{"mrn", "MRN: 00123456", "00123456", 0.85, "MRN label"},
+		{"mrn", "Medical Record No. A-778812", "A-778812", 0.85, "Medical record number label"},
+		{"mrn", "MRN: unknown", "", 0, "Label without a number"},
+		{"mrn", "record 00123456", "", 0, "Number without a label"},
+		{"health_plan", "Member ID: XYZ123456789", "XYZ123456789", 0.8, "Member ID"},
+		{"health_plan", "Policy #: H12345", "H12345", 0.8, "Policy number"},
+		{"health_plan", "member since 2019", "", 0, "No ID label"},
+	}
+
+	for _, tt := range testCases {
+		var detector Detector
+		for _, d := range Detectors() {
+			if d.Type() == tt.detectionType {
+				detector = d
+			}
+		}
+		if detector == nil {
+			t.Fatalf("no %s detector registered", tt.detectionType)
+		}
+
+		detections := detector.Detect(tt.text)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("%s Detect(%q) = %q; want no match (%s)", tt.detectionType, tt.text, detections[0].Value, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("%s Detect(%q) returned %d detections; want 1 (%s)", tt.detectionType, tt.text, len(detections), tt.comment)
+			continue
+		}
+		if d := detections[0]; d.Value != tt.value || d.Confidence != tt.confidence {
+			t.Errorf("%s Detect(%q) = %q confidence %.2f; want %q %.2f (%s)",
+				tt.detectionType, tt.text, d.Value, d.Confidence, tt.value, tt.confidence, tt.comment)
+		}
+	}
+}
+
+func TestLabelledIDField(t *testing.T) {
+	testCases := []struct {
+		field   string
+		value   string
+		want    string
+		comment string
+	}{
+		{"mrn", "00123456", "00123456", "MRN column"},
+		{"patients.medical_record_number", " A-778812 ", "A-778812", "Nested key, value trimmed"},
+		{"memberId", "XYZ123456789", "XYZ123456789", "Health plan key"},
+		{"notes", "00123456", "", "Field doesn't name an identifier"},
+		{"mrn", "pending", "", "No digits"},
+		{"mrn", "see chart 00123456", "", "Not a whole identifier"},
+	}
+
+	for _, tt := range testCases {
+		detections, err := checkField(tt.value, tt.field, 1)
+		if err != nil {
+			t.Fatalf("checkField(%q, %q) returned error: %v", tt.value, tt.field, err)
+		}
+		got := ""
+		for _, d := range detections {
+			if d.Type == "mrn" || d.Type == "health_plan" {
+				got = d.Value
+			}
+		}
+		if got != tt.want {
+			t.Errorf("checkField(%q, %q) found %q; want %q (%s)", tt.value, tt.field, got, tt.want, tt.comment)
+		}
+	}
+}
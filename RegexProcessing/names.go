@@ -0,0 +1,218 @@
+package RegexProcessing
+
+import (
+	"bufio"
+	"embed"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"goScan/ReadFunctions"
+	"goScan/utilityFunctions"
+)
+
+//go:embed nameData/*.txt
+var nameData embed.FS
+
+// Name dictionaries, loaded once from nameData. The name lists map each lower case
+// name to a weight between 0.5 and 1 that falls with its frequency rank.
+var (
+	firstNames = loadRankedWords("nameData/firstNames.txt")
+	surnames   = loadRankedWords("nameData/surnames.txt")
+	stopwords  = loadWordSet("nameData/stopwords.txt")
+)
+
+// nameCueRegex finds titles and labels that introduce a name, at the end of the
+// text before a candidate: "Mr. ", "Dr ", "Patient: ", "Name - ".
+var nameCueRegex = regexp.MustCompile(`(?i)\b(mr|mrs|ms|miss|mx|dr|prof|rev|sir|madam|patient|name|member|insured|subscriber|guarantor|employee|attn|attention)\.?\s*[:\-]?\s*$`)
+
+// nameCueWindow is how many bytes before a candidate nameCueRegex is looked for in.
+const nameCueWindow = 24
+
+// organisationRegex finds the words that show capitalised words name a company or
+// institution rather than a person.
+var organisationRegex = regexp.MustCompile(`(?i)\b(inc|llc|ltd|corp|corporation|plc|gmbh|company|foundation|university|college)\b`)
+
+// minNameConfidence is the score below which name candidates are dropped.
+const minNameConfidence = 0.2
+
+// nameDetector reports people's names. Runs of capitalised words found by
+// nameRegex are trimmed of stopwords and acronyms, then scored on whether they
+// start with a known first name, end with a known surname, and follow a title or
+// label such as "Dr." or "Patient:". Words that are also ordinary English ("Will",
+// "Rose", "Young") count for half, so capitalised prose and headings score too low
+// to report.
+type nameDetector struct{}
+
+func (n nameDetector) Type() string { return "name" }
+
+func (n nameDetector) ContextRules() ContextRules {
+	return ContextRules{Negative: organisationRegex, Window: nameCueWindow}
+}
+
+// nameToken is a capitalised word within a nameRegex match.
+type nameToken struct {
+	start, end int // offsets in the line
+	first      float64
+	surname    float64
+	stopword   bool
+}
+
+// isName reports whether t could be part of a name at all.
+func (t nameToken) isName() bool {
+	return t.first > 0 || t.surname > 0 || !t.stopword
+}
+
+func (n nameDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+
+	for _, loc := range nameRegex.FindAllStringIndex(line, -1) {
+		var run []nameToken
+		flush := func() {
+			if d, ok := scoreName(line, run); ok {
+				detections = append(detections, d)
+			}
+			run = nil
+		}
+
+		for _, tok := range splitNameTokens(line, loc[0], loc[1]) {
+			if !tok.isName() {
+				flush()
+				continue
+			}
+			run = append(run, tok)
+		}
+		flush()
+	}
+
+	return detections
+}
+
+// splitNameTokens splits line[start:end] at whitespace into classified tokens.
+func splitNameTokens(line string, start, end int) []nameToken {
+	var tokens []nameToken
+	for i := start; i < end; {
+		for i < end && unicode.IsSpace(rune(line[i])) {
+			i++
+		}
+		j := i
+		for j < end && !unicode.IsSpace(rune(line[j])) {
+			j++
+		}
+		if j > i {
+			tokens = append(tokens, classifyNameToken(line[i:j], i, j))
+		}
+		i = j
+	}
+	return tokens
+}
+
+// classifyNameToken looks word up in the dictionaries. Hyphenated names take the
+// best of their parts, and short all-caps words not in the name lists are treated
+// as acronyms.
+func classifyNameToken(word string, start, end int) nameToken {
+	tok := nameToken{start: start, end: end}
+	lower := strings.ToLower(word)
+
+	tok.first, tok.surname = firstNames[lower], surnames[lower]
+	for _, part := range strings.Split(lower, "-") {
+		tok.first = max(tok.first, firstNames[part])
+		tok.surname = max(tok.surname, surnames[part])
+	}
+
+	tok.stopword = stopwords[lower]
+	if tok.first == 0 && tok.surname == 0 && len(word) <= 4 && strings.ToUpper(word) == word {
+		tok.stopword = true
+	}
+	if tok.stopword {
+		tok.first /= 2
+		tok.surname /= 2
+	}
+	return tok
+}
+
+// scoreName turns a run of name-like tokens into a detection if it scores at
+// least minNameConfidence.
+func scoreName(line string, run []nameToken) (ReadFunctions.PIIDetection, bool) {
+	if len(run) == 0 || len(run) > 4 {
+		return ReadFunctions.PIIDetection{}, false
+	}
+	first, last := run[0], run[len(run)-1]
+
+	start, end := first.start, last.end
+	cue := nameCueRegex.MatchString(line[max(start-nameCueWindow, 0):start])
+
+	var confidence float64
+	switch {
+	case len(run) == 1:
+		// A lone word needs to be a name and nothing else.
+		if !first.stopword && (first.first > 0 || first.surname > 0) {
+			confidence = max(0.2+0.15*first.first, 0.15+0.1*first.surname)
+		}
+	case len(run) > 2 && first.first == 0 && !cue:
+		// Longer runs must open with a first name, or they are usually headings.
+	case first.first > 0 || last.surname > 0:
+		confidence = 0.35 + 0.35*first.first + 0.25*last.surname
+	}
+
+	if cue {
+		confidence = max(confidence, 0.35) + 0.25
+	}
+	confidence = min(confidence, 0.95)
+
+	if confidence < minNameConfidence {
+		return ReadFunctions.PIIDetection{}, false
+	}
+
+	match := line[start:end]
+	return ReadFunctions.PIIDetection{
+		Type:          "name",
+		Value:         match,
+		RedactedValue: redactAll(match),
+		StartOffset:   start,
+		EndOffset:     end,
+		Confidence:    confidence,
+	}, true
+}
+
+// loadRankedWords reads a word list in frequency order and weights each word from
+// 1 for the most frequent down to 0.5 for the least.
+func loadRankedWords(path string) map[string]float64 {
+	words := readWordList(path)
+	ranked := make(map[string]float64, len(words))
+	for i, w := range words {
+		if _, ok := ranked[w]; !ok {
+			ranked[w] = 1 - 0.5*float64(i)/float64(len(words))
+		}
+	}
+	return ranked
+}
+
+func loadWordSet(path string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range readWordList(path) {
+		set[w] = true
+	}
+	return set
+}
+
+// readWordList returns the lower-cased words in an embedded list, skipping blank
+// lines and # comments.
+func readWordList(path string) []string {
+	f, err := nameData.Open(path)
+	if err != nil {
+		panic(err) // embedded at build time, so only a packaging mistake gets here
+	}
+	defer utilityFunctions.SafeClose(f)
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, strings.ToLower(line))
+	}
+	return words
+}
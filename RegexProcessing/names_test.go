@@ -0,0 +1,57 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestNameDetector(t *testing.T) {
+	testCases := []struct {
+		text    string
+		value   string  // "" when no name should be found
+		atLeast float64 // minimum Confidence
+		atMost  float64 // maximum Confidence
+		comment string
+	}{
+		{"John Smith", "John Smith", 0.85, 0.95, "Common first name and surname"},
+		{"Patient: Mary Johnson", "Mary Johnson", 0.95, 0.95, "Label cue"},
+		{"Dr. Xavier Quillfeather", "Xavier Quillfeather", 0.55, 0.7, "Title cue with unknown names"},
+		{"Contact Sarah Davis today", "Sarah Davis", 0.85, 0.95, "Leading stopword trimmed"},
+		{"Hello John", "John", 0.3, 0.35, "First name alone"},
+		{"Mary-Kate Olsen", "Mary-Kate Olsen", 0.85, 0.95, "Hyphenated first name"},
+		{"Will Smith", "Will Smith", 0.7, 0.85, "Ambiguous first name with surname"},
+		{"JOHN SMITH", "JOHN SMITH", 0.85, 0.95, "All caps name"},
+		{"Emily Quillfeather", "Emily Quillfeather", 0.6, 0.7, "Known first name, unknown surname"},
+
+		{"The", "", 0, 0, "Article"},
+		{"And", "", 0, 0, "Conjunction"},
+		{"API", "", 0, 0, "Acronym"},
+		{"JSON", "", 0, 0, "Acronym"},
+		{"Apple", "", 0, 0, "Unknown single word"},
+		{"Will", "", 0, 0, "Ordinary word that is also a name"},
+		{"The Quick Brown Fox", "", 0, 0, "Title case prose"},
+		{"Monday Morning Report", "", 0, 0, "Calendar words"},
+	}
+
+	detector := nameDetector{}
+	for _, tt := range testCases {
+		detections := detector.Detect(tt.text)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("Detect(%q) = %q (%.2f); want no match (%s)", tt.text, detections[0].Value, detections[0].Confidence, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("Detect(%q) returned %d detections; want 1 (%s)", tt.text, len(detections), tt.comment)
+			continue
+		}
+		d := detections[0]
+		if d.Value != tt.value || d.Confidence < tt.atLeast || d.Confidence > tt.atMost {
+			t.Errorf("Detect(%q) = %q confidence %.2f; want %q in [%.2f, %.2f] (%s)",
+				tt.text, d.Value, d.Confidence, tt.value, tt.atLeast, tt.atMost, tt.comment)
+		}
+		if tt.text[d.StartOffset:d.EndOffset] != d.Value {
+			t.Errorf("Detect(%q) offsets [%d:%d] don't cover %q", tt.text, d.StartOffset, d.EndOffset, d.Value)
+		}
+	}
+}
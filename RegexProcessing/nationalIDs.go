@@ -0,0 +1,405 @@
+package RegexProcessing
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"goScan/ReadFunctions"
+)
+
+// nationalIDScheme is one country's national identifier, found by a codeDetector
+// and reported as "national_id" with subtype.
+type nationalIDScheme struct {
+	locale  string
+	subtype string
+	code    codeDetector
+}
+
+// nationalIDSchemes lists the supported schemes. Where two could match the same
+// digits, the one with the stronger check comes first and wins.
+var nationalIDSchemes = []nationalIDScheme{
+	{"uk", "uk_nino", codeDetector{
+		pattern:    regexp.MustCompile(`\b[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`),
+		keyword:    regexp.MustCompile(`(?i)\b(nino|national\s+insurance|ni\s+(?:number|no))\b`),
+		validate:   ninoValid,
+		confidence: 0.85, keywordConfidence: 0.95,
+	}},
+	{"it", "it_codice_fiscale", codeDetector{
+		pattern:    regexp.MustCompile(`\b[A-Z]{6}[0-9LMNP-V]{2}[ABCDEHLMPRST][0-9LMNP-V]{2}[A-Z][0-9LMNP-V]{3}[A-Z]\b`),
+		keyword:    regexp.MustCompile(`(?i)\b(codice\s+fiscale|cod\.?\s*fisc|c\.?f\.?)\b`),
+		validate:   codiceFiscaleValid,
+		confidence: 0.95, keywordConfidence: 0.99,
+	}},
+	{"es", "es_dni", codeDetector{
+		pattern:    regexp.MustCompile(`\b\d{8}-?[A-Z]\b`),
+		keyword:    regexp.MustCompile(`(?i)\b(dni|nif|documento\s+nacional)\b`),
+		validate:   dniValid,
+		confidence: 0.85, keywordConfidence: 0.95,
+	}},
+	{"es", "es_nie", codeDetector{
+		pattern:    regexp.MustCompile(`\b[XYZ]-?\d{7}-?[A-Z]\b`),
+		keyword:    regexp.MustCompile(`(?i)\b(nie|extranjero)\b`),
+		validate:   nieValid,
+		confidence: 0.85, keywordConfidence: 0.95,
+	}},
+	{"in", "in_pan", codeDetector{
+		pattern:    regexp.MustCompile(`\b[A-Z]{3}[ABCFGHJLPT][A-Z]\d{4}[A-Z]\b`),
+		keyword:    regexp.MustCompile(`(?i)\b(pan|permanent\s+account|income\s+tax)\b`),
+		confidence: 0.7, keywordConfidence: 0.9,
+	}},
+	{"fr", "fr_insee", codeDetector{
+		pattern:    regexp.MustCompile(`\b[12] ?\d{2} ?\d{2} ?(?:\d{2}|2[AB]) ?\d{3} ?\d{3} ?\d{2}\b`),
+		keyword:    regexp.MustCompile(`(?i)\b(insee|nir|s[ée]curit[ée]\s+sociale|num[ée]ro\s+de\s+s[ée]cu)`),
+		validate:   inseeValid,
+		confidence: 0.8, keywordConfidence: 0.95,
+	}},
+	{"br", "br_cpf", codeDetector{
+		pattern:    regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`),
+		keyword:    regexp.MustCompile(`(?i)\bcpf\b`),
+		validate:   cpfValid,
+		weak:       func(cpf string) bool { return !strings.Contains(cpf, "-") },
+		confidence: 0.85, keywordConfidence: 0.95,
+	}},
+	{"in", "in_aadhaar", codeDetector{
+		pattern:    regexp.MustCompile(`\b[2-9]\d{3}[ -]?\d{4}[ -]?\d{4}\b`),
+		keyword:    regexp.MustCompile(`(?i)\b(aadhaa?r|uidai|uid)\b`),
+		validate:   func(id string) bool { return verhoeffValid(digitsOnly(id)) },
+		weak:       unseparated,
+		confidence: 0.6, keywordConfidence: 0.95,
+	}},
+	{"uk", "uk_nhs", codeDetector{
+		pattern:           regexp.MustCompile(`\b\d{3}[ -]?\d{3}[ -]?\d{4}\b`),
+		keyword:           regexp.MustCompile(`(?i)\bnhs\b`),
+		validate:          nhsValid,
+		weak:              always,
+		keywordConfidence: 0.95,
+	}},
+	{"de", "de_steuer_id", codeDetector{
+		pattern:           regexp.MustCompile(`\b[1-9]\d ?\d{3} ?\d{3} ?\d{3}\b`),
+		keyword:           regexp.MustCompile(`(?i)\b(steuer-?id|steuerliche\s+identifikationsnummer|steueridentifikationsnummer|idnr|tax\s+id)\b`),
+		validate:          steuerIDValid,
+		weak:              always,
+		keywordConfidence: 0.9,
+	}},
+	{"nl", "nl_bsn", codeDetector{
+		pattern:           regexp.MustCompile(`\b\d{4}\.?\d{2}\.?\d{3}\b|\b\d{3}\.\d{3}\.\d{3}\b`),
+		keyword:           regexp.MustCompile(`(?i)\b(bsn|burgerservicenummer|sofi)\b`),
+		validate:          func(bsn string) bool { return bsnValid(digitsOnly(bsn)) },
+		weak:              always,
+		keywordConfidence: 0.9,
+	}},
+	{"ca", "ca_sin", codeDetector{
+		pattern:    regexp.MustCompile(`\b\d{3}[ -]?\d{3}[ -]?\d{3}\b`),
+		keyword:    regexp.MustCompile(`(?i)\b(sin|social\s+insurance|nas)\b`),
+		validate:   sinValid,
+		weak:       unseparated,
+		confidence: 0.5, keywordConfidence: 0.9,
+	}},
+}
+
+// nationalIDDetector reports the national identifiers of the schemes it was built
+// with, each checked against its format and check digit. Schemes whose numbers are
+// easily mistaken for other digits only report matches with a keyword nearby.
+type nationalIDDetector struct {
+	schemes []nationalIDScheme
+}
+
+func (n nationalIDDetector) Type() string { return "national_id" }
+
+func (n nationalIDDetector) ContextRules() ContextRules {
+	return ContextRules{Negative: referenceNumberRegex, Window: keywordWindow}
+}
+
+func (n nationalIDDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	return n.detect(func(_ string, c codeDetector) []ReadFunctions.PIIDetection { return c.Detect(line) })
+}
+
+// DetectField treats a column or key as the keyword for a scheme only when it names
+// that scheme, such as "bsn", or national IDs in general, such as "national_id".
+func (n nationalIDDetector) DetectField(field, value string) []ReadFunctions.PIIDetection {
+	return n.detect(func(subtype string, c codeDetector) []ReadFunctions.PIIDetection {
+		if !fieldNamesType(field, n.Type()) && !fieldNamesType(field, subtype) {
+			return nil
+		}
+		return c.detect(value, true)
+	})
+}
+
+// detect runs each scheme with find, dropping matches an earlier scheme covered.
+func (n nationalIDDetector) detect(find func(subtype string, c codeDetector) []ReadFunctions.PIIDetection) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+	var covered [][2]int
+
+	for _, scheme := range n.schemes {
+		code := scheme.code
+		code.detectionType = n.Type()
+		code.redact = redactKeepLast4
+		for _, d := range find(scheme.subtype, code) {
+			if overlapsAny(covered, d.StartOffset, d.EndOffset) {
+				continue
+			}
+			covered = append(covered, [2]int{d.StartOffset, d.EndOffset})
+			d.Subtype = scheme.subtype
+			detections = append(detections, d)
+		}
+	}
+
+	return detections
+}
+
+// Locales returns the locales SetLocales accepts.
+func Locales() []string {
+	locales := []string{"us"}
+	seen := map[string]bool{"us": true}
+	for _, scheme := range nationalIDSchemes {
+		if !seen[scheme.locale] {
+			seen[scheme.locale] = true
+			locales = append(locales, scheme.locale)
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// SetLocales chooses which countries' national identifiers are detected, by
+// registering a "national_id" detector for their schemes. US identifiers are always
+// detected, so "us" adds nothing; "all" selects every country. With no other
+// locales the national_id detector is unregistered.
+func SetLocales(locales []string) error {
+	known := map[string]bool{}
+	for _, locale := range Locales() {
+		known[locale] = true
+	}
+
+	enabled := map[string]bool{}
+	for _, locale := range locales {
+		locale = strings.ToLower(strings.TrimSpace(locale))
+		switch {
+		case locale == "":
+		case locale == "all":
+			for l := range known {
+				enabled[l] = true
+			}
+		case known[locale]:
+			enabled[locale] = true
+		default:
+			return fmt.Errorf("unknown locale %q, want one of %s or all", locale, strings.Join(Locales(), ", "))
+		}
+	}
+
+	var schemes []nationalIDScheme
+	for _, scheme := range nationalIDSchemes {
+		if enabled[scheme.locale] {
+			schemes = append(schemes, scheme)
+		}
+	}
+
+	Unregister("national_id")
+	if len(schemes) == 0 {
+		return nil
+	}
+	return Register(nationalIDDetector{schemes: schemes})
+}
+
+// ninoValid rejects the NINO prefixes HMRC never issues.
+func ninoValid(nino string) bool {
+	switch nino[:2] {
+	case "BG", "GB", "NK", "KN", "TN", "NT", "ZZ":
+		return false
+	}
+	return true
+}
+
+// nhsValid checks an NHS number's mod 11 check digit: the first nine digits weighted
+// 10 down to 2, with the check digit 11 minus the remainder (11 becomes 0 and 10 is
+// never issued).
+func nhsValid(number string) bool {
+	digits := digitsOnly(number)
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(digits[i]-'0')
+	}
+	check := 11 - sum%11
+	if check == 11 {
+		check = 0
+	}
+	return check != 10 && check == int(digits[9]-'0')
+}
+
+// sinValid checks a Canadian SIN: Luhn valid, and not starting with 0 or 8, which
+// are never assigned.
+func sinValid(sin string) bool {
+	digits := digitsOnly(sin)
+	return digits[0] != '0' && digits[0] != '8' && luhnValid(digits)
+}
+
+// verhoeffD, verhoeffP are the Verhoeff multiplication and permutation tables.
+var (
+	verhoeffD = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, {1, 2, 3, 4, 0, 6, 7, 8, 9, 5}, {2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7}, {4, 0, 1, 2, 3, 9, 5, 6, 7, 8}, {5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2}, {7, 6, 5, 9, 8, 2, 1, 0, 4, 3}, {8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffP = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, {1, 5, 7, 6, 2, 8, 3, 0, 9, 4}, {5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7}, {9, 4, 5, 3, 1, 2, 7, 0, 6, 8}, {4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5}, {7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+)
+
+// verhoeffValid checks the Verhoeff check digit used by Aadhaar numbers.
+func verhoeffValid(digits string) bool {
+	c := 0
+	for i := 0; i < len(digits); i++ {
+		c = verhoeffD[c][verhoeffP[i%8][digits[len(digits)-1-i]-'0']]
+	}
+	return c == 0
+}
+
+// dniLetters maps a DNI or NIE number mod 23 to its control letter.
+const dniLetters = "TRWAGMYFPDXBNJZSQVHLCKE"
+
+// dniValid checks a Spanish DNI's control letter.
+func dniValid(dni string) bool {
+	return dniLetters[atoi(dni[:8])%23] == dni[len(dni)-1]
+}
+
+// nieValid checks a Spanish NIE's control letter, with X, Y and Z read as 0, 1 and 2.
+func nieValid(nie string) bool {
+	digits := string(rune('0'+strings.IndexByte("XYZ", nie[0]))) + digitsOnly(nie)
+	return dniLetters[atoi(digits)%23] == nie[len(nie)-1]
+}
+
+// codiceFiscaleOdd is the value of each character (0-9 then A-Z) in the odd
+// positions of a codice fiscale; even positions use the character's own value.
+var codiceFiscaleOdd = [36]int{
+	1, 0, 5, 7, 9, 13, 15, 17, 19, 21,
+	1, 0, 5, 7, 9, 13, 15, 17, 19, 21, 2, 4, 18, 20, 11, 3, 6, 8, 12, 14, 16, 10, 22, 25, 24, 23,
+}
+
+// codiceFiscaleValid checks an Italian codice fiscale's control character.
+func codiceFiscaleValid(cf string) bool {
+	sum := 0
+	for i := 0; i < 15; i++ {
+		c := cf[i]
+		index, value := int(c-'0'), int(c-'0')
+		if c >= 'A' {
+			index, value = int(c-'A')+10, int(c-'A')
+		}
+		if i%2 == 0 { // odd position, counting from 1
+			sum += codiceFiscaleOdd[index]
+		} else {
+			sum += value
+		}
+	}
+	return byte('A'+sum%26) == cf[15]
+}
+
+// inseeValid checks a French INSEE (NIR) number: a birth month of 01-12, or 20 and
+// above for records without one, and a key of 97 minus the first 13 digits mod 97,
+// with Corsica's 2A and 2B read as 19 and 18.
+func inseeValid(nir string) bool {
+	nir = strings.ReplaceAll(nir, " ", "")
+	if month := atoi(nir[3:5]); month == 0 || month > 12 && month < 20 {
+		return false
+	}
+
+	body := nir[:13]
+	body = strings.Replace(body, "2A", "19", 1)
+	body = strings.Replace(body, "2B", "18", 1)
+	remainder := 0
+	for i := 0; i < len(body); i++ {
+		remainder = (remainder*10 + int(body[i]-'0')) % 97
+	}
+	return 97-remainder == atoi(nir[13:])
+}
+
+// steuerIDValid checks a German tax ID: among the first ten digits exactly one digit
+// appears two or three times, and the last digit is the ISO 7064 MOD 11,10 check.
+func steuerIDValid(id string) bool {
+	digits := strings.ReplaceAll(id, " ", "")
+
+	var counts [10]int
+	for i := 0; i < 10; i++ {
+		counts[digits[i]-'0']++
+	}
+	repeated := 0
+	for _, n := range counts {
+		switch {
+		case n > 3:
+			return false
+		case n > 1:
+			repeated++
+		}
+	}
+	if repeated != 1 {
+		return false
+	}
+
+	product := 10
+	for i := 0; i < 10; i++ {
+		sum := (int(digits[i]-'0') + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = sum * 2 % 11
+	}
+	check := 11 - product
+	if check == 10 {
+		check = 0
+	}
+	return check == int(digits[10]-'0')
+}
+
+// bsnValid applies the Dutch 11-proof: the first eight digits weighted 9 down to 2,
+// less the ninth, is a multiple of 11.
+func bsnValid(digits string) bool {
+	if len(digits) != 9 {
+		return false
+	}
+	sum := -int(digits[8] - '0')
+	for i := 0; i < 8; i++ {
+		sum += (9 - i) * int(digits[i]-'0')
+	}
+	return sum != 0 && sum%11 == 0
+}
+
+// cpfValid checks a Brazilian CPF's two mod 11 check digits. Numbers of one repeated
+// digit pass the arithmetic but are never issued.
+func cpfValid(cpf string) bool {
+	digits := digitsOnly(cpf)
+	if strings.Count(digits, digits[:1]) == len(digits) {
+		return false
+	}
+	for n := 9; n <= 10; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += (n + 1 - i) * int(digits[i]-'0')
+		}
+		check := sum * 10 % 11 % 10
+		if check != int(digits[n]-'0') {
+			return false
+		}
+	}
+	return true
+}
+
+// unseparated reports numbers written without spaces or dashes, which look like any
+// other run of digits.
+func unseparated(match string) bool {
+	return !strings.ContainsAny(match, " -")
+}
+
+// digitsOnly returns the digits in s.
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestNationalIDDetector(t *testing.T) {
+	testCases := []struct {
+		text       string
+		value      string // "" when nothing should be found
+		subtype    string
+		confidence float64
+		comment    string
+	}{
+		{"NINO: AB 12 34 56 C", "AB 12 34 56 C", "uk_nino", 0.95, "NINO with keyword"},
+		{"AB123456C", "AB123456C", "uk_nino", 0.85, "NINO without spaces"},
+		{"GB123456A", "", "", 0, "NINO prefix never issued"},
+		{"NHS number 943 476 5919", "943 476 5919", "uk_nhs", 0.95, "NHS number"},
+		{"NHS 943 476 5918", "", "", 0, "NHS check digit fails"},
+		{"call 943 476 5919", "", "", 0, "NHS number needs a keyword"},
+		{"SIN 130 692 544", "130 692 544", "ca_sin", 0.9, "SIN with keyword"},
+		{"130-692-544", "130-692-544", "ca_sin", 0.5, "Separated SIN"},
+		{"130692544", "", "", 0, "Bare nine digits need a keyword"},
+		{"130 692 545", "", "", 0, "SIN fails Luhn"},
+		{"SIN 046 454 286", "", "", 0, "SINs starting with 0 are never assigned"},
+		{"Aadhaar 2341 2341 2346", "2341 2341 2346", "in_aadhaar", 0.95, "Aadhaar"},
+		{"2341 2341 2345", "", "", 0, "Aadhaar Verhoeff fails"},
+		{"PAN ABCPE1234F", "ABCPE1234F", "in_pan", 0.9, "PAN with keyword"},
+		{"ABCQE1234F", "", "", 0, "Unknown PAN holder type"},
+		{"DNI 12345678Z", "12345678Z", "es_dni", 0.95, "DNI"},
+		{"12345678A", "", "", 0, "DNI letter wrong"},
+		{"X1234567L", "X1234567L", "es_nie", 0.85, "NIE"},
+		{"RSSMRA85T10A562S", "RSSMRA85T10A562S", "it_codice_fiscale", 0.95, "Codice fiscale"},
+		{"RSSMRA85T10A562T", "", "", 0, "Codice fiscale control letter wrong"},
+		{"NIR 1 85 05 78 006 084 91", "1 85 05 78 006 084 91", "fr_insee", 0.95, "INSEE with key"},
+		{"1 85 05 78 006 084 90", "", "", 0, "INSEE key wrong"},
+		{"Steuer-ID 86095742719", "86095742719", "de_steuer_id", 0.9, "German tax ID"},
+		{"Steuer-ID 86095742718", "", "", 0, "Tax ID check digit wrong"},
+		{"BSN 111222333", "111222333", "nl_bsn", 0.9, "BSN"},
+		{"BSN 111222334", "", "", 0, "BSN fails the 11-proof"},
+		{"CPF 529.982.247-25", "529.982.247-25", "br_cpf", 0.95, "CPF"},
+		{"529.982.247-25", "529.982.247-25", "br_cpf", 0.85, "Formatted CPF without keyword"},
+		{"111.111.111-11", "", "", 0, "Repeated digit CPF"},
+	}
+
+	detector := nationalIDDetector{schemes: nationalIDSchemes}
+	for _, tt := range testCases {
+		detections := detector.Detect(tt.text)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("Detect(%q) = %q (%s); want no match (%s)", tt.text, detections[0].Value, detections[0].Subtype, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("Detect(%q) returned %d detections; want 1 (%s)", tt.text, len(detections), tt.comment)
+			continue
+		}
+		if d := detections[0]; d.Value != tt.value || d.Subtype != tt.subtype || d.Confidence != tt.confidence {
+			t.Errorf("Detect(%q) = %q %s %.2f; want %q %s %.2f (%s)",
+				tt.text, d.Value, d.Subtype, d.Confidence, tt.value, tt.subtype, tt.confidence, tt.comment)
+		}
+	}
+}
+
+func TestNationalIDDetectField(t *testing.T) {
+	// 100174218 passes both the Canadian SIN Luhn check and the Dutch BSN 11-proof.
+	testCases := []struct {
+		field   string
+		value   string
+		subtype string // "" when nothing should be found
+		comment string
+	}{
+		{"sin", "100174218", "ca_sin", "SIN column only labels SINs"},
+		{"employee_SIN", "100174218", "ca_sin", "SIN as one token of the header"},
+		{"bsn", "100174218", "nl_bsn", "BSN column"},
+		{"national_id", "100174218", "nl_bsn", "Generic column labels every scheme, stronger check wins"},
+		{"nino", "100174218", "", "Column names another scheme"},
+		{"notes", "100174218", "", "Column names no national ID"},
+	}
+
+	detector := nationalIDDetector{schemes: nationalIDSchemes}
+	for _, tt := range testCases {
+		detections := detector.DetectField(tt.field, tt.value)
+		if tt.subtype == "" {
+			if len(detections) != 0 {
+				t.Errorf("DetectField(%q, %q) = %s; want no match (%s)", tt.field, tt.value, detections[0].Subtype, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 || detections[0].Subtype != tt.subtype {
+			t.Errorf("DetectField(%q, %q) = %+v; want one %s (%s)", tt.field, tt.value, detections, tt.subtype, tt.comment)
+		}
+	}
+}
+
+func TestSetLocales(t *testing.T) {
+	defer SetLocales(nil)
+
+	enabled := func() map[string]bool {
+		subtypes := map[string]bool{}
+		for _, d := range Detectors() {
+			if n, ok := d.(nationalIDDetector); ok {
+				for _, scheme := range n.schemes {
+					subtypes[scheme.subtype] = true
+				}
+			}
+		}
+		return subtypes
+	}
+
+	if err := SetLocales([]string{"us"}); err != nil || len(enabled()) != 0 {
+		t.Errorf("SetLocales(us) = %v with schemes %v; want none", err, enabled())
+	}
+	if err := SetLocales([]string{"UK", " nl"}); err != nil {
+		t.Fatalf("SetLocales(UK, nl) = %v", err)
+	}
+	if got := enabled(); len(got) != 3 || !got["uk_nino"] || !got["uk_nhs"] || !got["nl_bsn"] {
+		t.Errorf("SetLocales(UK, nl) enabled %v; want uk_nino, uk_nhs and nl_bsn", got)
+	}
+	if err := SetLocales([]string{"all"}); err != nil || len(enabled()) != len(nationalIDSchemes) {
+		t.Errorf("SetLocales(all) = %v with %d schemes; want %d", err, len(enabled()), len(nationalIDSchemes))
+	}
+	if err := SetLocales([]string{"xx"}); err == nil {
+		t.Errorf("SetLocales(xx) succeeded; want an error")
+	}
+}
@@ -0,0 +1,276 @@
+package RegexProcessing
+
+import (
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"goScan/ReadFunctions"
+)
+
+var (
+	// ipv4Regex matches dotted quads; ipDetector parses them to rule out 999.1.1.1.
+	ipv4Regex = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	// ipv6Regex matches candidate IPv6 addresses, including compressed and IPv4-mapped
+	// forms; ipDetector parses them to rule out times and MAC addresses.
+	ipv6Regex = regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,7}(?:(?:\d{1,3}\.){3}\d{1,3}|[0-9a-f]{1,4})?`)
+
+	// macRegex matches MAC addresses written with colons, dashes or Cisco's dots.
+	macRegex = regexp.MustCompile(`(?i)\b(?:[0-9a-f]{2}(?::[0-9a-f]{2}){5}|[0-9a-f]{2}(?:-[0-9a-f]{2}){5}|[0-9a-f]{4}\.[0-9a-f]{4}\.[0-9a-f]{4})\b`)
+
+	// imeiRegex matches 15 digit IMEIs, optionally grouped 2-6-6-1.
+	imeiRegex        = regexp.MustCompile(`\b\d{2}[ -]?\d{6}[ -]?\d{6}[ -]?\d\b`)
+	imeiKeywordRegex = regexp.MustCompile(`(?i)\b(imei|device|handset|serial)\b`)
+
+	// vinRegex matches 17 character vehicle identification numbers, which never use I, O or Q.
+	vinRegex        = regexp.MustCompile(`\b[A-HJ-NPR-Z0-9]{17}\b`)
+	vinKeywordRegex = regexp.MustCompile(`(?i)\b(vin|vehicle|chassis)\b`)
+
+	// ipKeywordRegex finds the words that label an address as a machine's, and
+	// versionRegex those that show four dotted numbers are a version.
+	ipKeywordRegex = regexp.MustCompile(`(?i)\b(ip|ipv4|ipv6|host|client|remote|src|dst|source|destination|login|logged\s+in)\b`)
+	versionRegex   = regexp.MustCompile(`(?i)\b(version|ver|v|release|revision|rev|firmware)\b`)
+
+	// urlKeywordRegex finds the words that introduce a person's own page.
+	urlKeywordRegex = regexp.MustCompile(`(?i)\b(profile|homepage|home\s+page|website|blog|portfolio|follow)\b`)
+
+	// urlRegex matches web addresses with a scheme or www, and profile links on the
+	// social networks that are often written without either.
+	urlRegex = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"'()]+|\bwww\.[^\s<>"'()]+|` +
+		`\b(?:facebook|instagram|twitter|x|linkedin|github|tiktok|youtube|reddit|medium)\.com/[^\s<>"'()]+`)
+)
+
+// ipDetector reports IPv4 and IPv6 addresses with Subtype "public" or "private".
+// Private and link-local addresses still identify a machine on a network, so they
+// are reported with privateConfidence; loopback, unspecified, multicast and
+// broadcast addresses identify nobody and are ignored.
+type ipDetector struct {
+	confidence        float64
+	privateConfidence float64
+}
+
+func (i ipDetector) Type() string { return "ip_address" }
+
+func (i ipDetector) ContextRules() ContextRules {
+	return ContextRules{Positive: ipKeywordRegex, Negative: versionRegex, Window: keywordWindow}
+}
+
+func (i ipDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+	var covered [][2]int
+
+	for _, re := range []*regexp.Regexp{ipv6Regex, ipv4Regex} {
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			start, end := loc[0], loc[1]
+			if !ipBoundary(line, start, end) || overlapsAny(covered, start, end) {
+				continue
+			}
+			match := line[start:end]
+			if strings.Contains(match, "::") && ipv6Groups(match) < minCompressedGroups {
+				continue
+			}
+			addr, err := netip.ParseAddr(match)
+			if err != nil {
+				continue
+			}
+
+			addr = addr.Unmap()
+			if addr.IsLoopback() || addr.IsUnspecified() || addr.IsMulticast() || addr == netip.AddrFrom4([4]byte{255, 255, 255, 255}) {
+				continue
+			}
+			subtype, confidence := "public", i.confidence
+			if addr.IsPrivate() || addr.IsLinkLocalUnicast() {
+				subtype, confidence = "private", i.privateConfidence
+			}
+
+			covered = append(covered, [2]int{start, end})
+			detections = append(detections, ReadFunctions.PIIDetection{
+				Type:          i.Type(),
+				Subtype:       subtype,
+				Value:         match,
+				RedactedValue: redactAll(match),
+				StartOffset:   start,
+				EndOffset:     end,
+				Confidence:    confidence,
+			})
+		}
+	}
+
+	return detections
+}
+
+// minCompressedGroups is the fewest groups an IPv6 address written with "::" must
+// show. Shorter ones such as "d::ab" are far more often C++ scopes like std::abs.
+const minCompressedGroups = 3
+
+// ipv6Groups counts the groups written out in an IPv6 address, where an embedded
+// IPv4 address stands for two.
+func ipv6Groups(addr string) int {
+	groups := 0
+	for _, part := range strings.Split(addr, ":") {
+		switch {
+		case strings.Contains(part, "."):
+			groups += 2
+		case part != "":
+			groups++
+		}
+	}
+	return groups
+}
+
+// ipBoundary reports whether line[start:end] stands alone rather than being part of
+// a longer dotted or colon-separated sequence such as an OID or a version number. An
+// IPv4 address may be followed by a colon and port.
+func ipBoundary(line string, start, end int) bool {
+	if start > 0 && (isHexDigit(line[start-1]) || line[start-1] == '.' || line[start-1] == ':') {
+		return false
+	}
+	if end < len(line) && (isHexDigit(line[end]) || line[end] == ':' && strings.Contains(line[start:end], ":")) {
+		return false
+	}
+	return end+1 >= len(line) || line[end] != '.' || !isDigit(line[end+1])
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// macValid rejects the all-zero and broadcast addresses, which name no device.
+func macValid(mac string) bool {
+	hex := strings.ToLower(strings.NewReplacer(":", "", "-", "", ".", "").Replace(mac))
+	return hex != "000000000000" && hex != "ffffffffffff"
+}
+
+// imeiValid checks an IMEI's Luhn check digit.
+func imeiValid(imei string) bool {
+	return luhnValid(digitsOnly(imei))
+}
+
+// vinWeights are the position weights for a VIN's check digit; position 9 is the
+// check digit itself.
+var vinWeights = [17]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// vinValid checks a VIN's check digit: letters are transliterated to digits, each
+// character weighted by position, and the sum mod 11 (10 written as X) must be the
+// 9th character. A VIN also has letters as well as digits.
+func vinValid(vin string) bool {
+	sum, letters := 0, 0
+	for i := 0; i < len(vin); i++ {
+		c := vin[i]
+		value := int(c - '0')
+		if !isDigit(c) {
+			letters++
+			value = int("12345678012345070923456789"[c-'A'] - '0')
+		}
+		sum += value * vinWeights[i]
+	}
+	if letters == 0 || letters == len(vin) {
+		return false
+	}
+
+	check := byte('0' + sum%11)
+	if sum%11 == 10 {
+		check = 'X'
+	}
+	return vin[8] == check
+}
+
+// socialProfileHosts maps social network hosts to how a profile path starts; an
+// empty prefix means the first path segment is the user name.
+var socialProfileHosts = map[string]string{
+	"facebook.com": "", "fb.com": "", "instagram.com": "", "twitter.com": "", "x.com": "",
+	"github.com": "", "pinterest.com": "", "linkedin.com": "in/", "tiktok.com": "@",
+	"youtube.com": "@", "medium.com": "@", "threads.net": "@", "reddit.com": "user/",
+}
+
+// nonProfilePaths are first path segments on social networks that aren't user names.
+var nonProfilePaths = map[string]bool{
+	"about": true, "explore": true, "hashtag": true, "help": true, "home": true, "intent": true,
+	"login": true, "p": true, "pages": true, "policies": true, "privacy": true, "search": true,
+	"settings": true, "share": true, "sharer": true, "signup": true, "terms": true, "watch": true,
+	"features": true, "marketplace": true, "orgs": true, "topics": true, "groups": true, "i": true,
+}
+
+// personalHostSuffixes are hosting domains that give each user their own subdomain.
+var personalHostSuffixes = []string{
+	".github.io", ".wordpress.com", ".blogspot.com", ".tumblr.com", ".substack.com", ".neocities.org",
+}
+
+// urlDetector reports URLs that identify a person: social network profiles, with
+// Subtype "social_profile", and personal pages such as ~user directories and blogs
+// on per-user subdomains, with Subtype "personal_page". Other URLs are ignored.
+type urlDetector struct {
+	profileConfidence float64
+	pageConfidence    float64
+}
+
+func (u urlDetector) Type() string { return "url" }
+
+func (u urlDetector) ContextRules() ContextRules {
+	return ContextRules{Positive: urlKeywordRegex, Negative: sampleDataRegex, Window: keywordWindow}
+}
+
+func (u urlDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+
+	for _, loc := range urlRegex.FindAllStringIndex(line, -1) {
+		match := strings.TrimRight(line[loc[0]:loc[1]], ".,;:!?")
+		subtype := personalURL(match)
+		if subtype == "" {
+			continue
+		}
+		confidence := u.profileConfidence
+		if subtype == "personal_page" {
+			confidence = u.pageConfidence
+		}
+
+		detections = append(detections, ReadFunctions.PIIDetection{
+			Type:          u.Type(),
+			Subtype:       subtype,
+			Value:         match,
+			RedactedValue: redactAll(match),
+			StartOffset:   loc[0],
+			EndOffset:     loc[0] + len(match),
+			Confidence:    confidence,
+		})
+	}
+
+	return detections
+}
+
+// personalURL classifies raw as "social_profile", "personal_page" or "" for a URL
+// that doesn't identify anyone.
+func personalURL(raw string) string {
+	if !strings.Contains(strings.ToLower(raw[:min(len(raw), 8)]), "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	path := strings.Trim(u.Path, "/")
+
+	if prefix, ok := socialProfileHosts[host]; ok {
+		if !strings.HasPrefix(strings.ToLower(path), prefix) {
+			return ""
+		}
+		name, _, _ := strings.Cut(path[len(prefix):], "/")
+		if name == "" || nonProfilePaths[strings.ToLower(name)] {
+			return ""
+		}
+		return "social_profile"
+	}
+
+	if strings.HasPrefix(path, "~") && len(path) > 1 {
+		return "personal_page"
+	}
+	for _, suffix := range personalHostSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return "personal_page"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestNetworkIdentifiers(t *testing.T) {
+	testCases := []struct {
+		detectionType string
+		text          string
+		value         string // "" when nothing should be found
+		subtype       string
+		confidence    float64
+		comment       string
+	}{
+		{"ip_address", "login from 203.0.113.45 at noon", "203.0.113.45", "public", 0.8, "Public IPv4"},
+		{"ip_address", "gateway 192.168.1.1", "192.168.1.1", "private", 0.5, "Private IPv4"},
+		{"ip_address", "host 10.0.0.7:8080", "10.0.0.7", "private", 0.5, "IPv4 with port"},
+		{"ip_address", "client 2001:db8:85a3::8a2e:370:7334", "2001:db8:85a3::8a2e:370:7334", "public", 0.8, "Compressed IPv6"},
+		{"ip_address", "fe80::1ff:fe23:4567:890a", "fe80::1ff:fe23:4567:890a", "private", 0.5, "Link-local IPv6"},
+		{"ip_address", "::ffff:192.168.0.5", "::ffff:192.168.0.5", "private", 0.5, "IPv4-mapped IPv6"},
+		{"ip_address", "localhost 127.0.0.1", "", "", 0, "Loopback"},
+		{"ip_address", "999.1.1.1", "", "", 0, "Octet out of range"},
+		{"ip_address", "OID 1.3.6.1.4.1", "", "", 0, "Part of a longer dotted sequence"},
+		{"ip_address", "meet at 10:30:00", "", "", 0, "Time"},
+		{"ip_address", "return std::abs(x);", "", "", 0, "C++ scope"},
+		{"ip_address", "Foo::Bar::ace::fed()", "", "", 0, "Nested C++ scopes"},
+		{"ip_address", "fe80::1", "", "", 0, "Too few groups to tell from a scope"},
+		{"mac_address", "hw 00:1A:2B:3C:4D:5E", "00:1A:2B:3C:4D:5E", "", 0.8, "Colon MAC"},
+		{"mac_address", "00-1a-2b-3c-4d-5e", "00-1a-2b-3c-4d-5e", "", 0.8, "Dash MAC"},
+		{"mac_address", "001a.2b3c.4d5e", "001a.2b3c.4d5e", "", 0.8, "Cisco MAC"},
+		{"mac_address", "ff:ff:ff:ff:ff:ff", "", "", 0, "Broadcast"},
+		{"imei", "IMEI 490154203237518", "490154203237518", "", 0.9, "IMEI with keyword"},
+		{"imei", "49-015420-323751-8", "49-015420-323751-8", "", 0.6, "Grouped IMEI"},
+		{"imei", "490154203237518", "", "", 0, "Bare 15 digits need a keyword"},
+		{"imei", "IMEI 490154203237519", "", "", 0, "IMEI fails Luhn"},
+		{"vin", "VIN: 1M8GDM9AXKP042788", "1M8GDM9AXKP042788", "", 0.95, "VIN with X check digit"},
+		{"vin", "1HGCM82633A004352", "1HGCM82633A004352", "", 0.85, "VIN without keyword"},
+		{"vin", "1M8GDM9A1KP042788", "", "", 0, "Wrong check digit"},
+		{"url", "see https://www.linkedin.com/in/jane-doe-123/", "https://www.linkedin.com/in/jane-doe-123/", "social_profile", 0.8, "LinkedIn profile"},
+		{"url", "follow twitter.com/janedoe.", "twitter.com/janedoe", "social_profile", 0.8, "Bare profile link, trailing period trimmed"},
+		{"url", "http://example.edu/~jdoe/cv.html", "http://example.edu/~jdoe/cv.html", "personal_page", 0.6, "Tilde home page"},
+		{"url", "blog at https://janedoe.github.io", "https://janedoe.github.io", "personal_page", 0.6, "Per-user subdomain"},
+		{"url", "https://twitter.com/search?q=x", "", "", 0, "Not a profile"},
+		{"url", "https://www.example.com/products", "", "", 0, "Ordinary site"},
+	}
+
+	for _, tt := range testCases {
+		var detector Detector
+		for _, d := range Detectors() {
+			if d.Type() == tt.detectionType {
+				detector = d
+			}
+		}
+		if detector == nil {
+			t.Fatalf("no %s detector registered", tt.detectionType)
+		}
+
+		detections := detector.Detect(tt.text)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("%s Detect(%q) = %q; want no match (%s)", tt.detectionType, tt.text, detections[0].Value, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("%s Detect(%q) returned %d detections; want 1 (%s)", tt.detectionType, tt.text, len(detections), tt.comment)
+			continue
+		}
+		d := detections[0]
+		if d.Value != tt.value || d.Subtype != tt.subtype || d.Confidence != tt.confidence {
+			t.Errorf("%s Detect(%q) = %q %q %.2f; want %q %q %.2f (%s)",
+				tt.detectionType, tt.text, d.Value, d.Subtype, d.Confidence, tt.value, tt.subtype, tt.confidence, tt.comment)
+		}
+		if tt.text[d.StartOffset:d.EndOffset] != d.Value {
+			t.Errorf("%s Detect(%q) offsets [%d:%d] don't cover %q", tt.detectionType, tt.text, d.StartOffset, d.EndOffset, d.Value)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"goScan/ReadFunctions"
+)
+
+// cardRegex finds runs of 13 to 19 digits, optionally grouped with spaces or dashes.
+// Candidates are checked by cardDetector against issuer ranges and the Luhn checksum.
+var cardRegex = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// cardIssuer is a range of issuer identification numbers (the leading digits of a
+// card number) and the card lengths the issuer uses within it.
+type cardIssuer struct {
+	brand   string
+	low     int // inclusive range of the leading digits
+	high    int
+	digits  int // how many leading digits low and high cover
+	lengths []int
+}
+
+// cardIssuers lists the supported brands. More specific ranges come first, since
+// the first match wins (Discover's 622126-622925 sits inside UnionPay's 62).
+var cardIssuers = []cardIssuer{
+	{brand: "discover", low: 622126, high: 622925, digits: 6, lengths: []int{16, 17, 18, 19}},
+	{brand: "visa", low: 4, high: 4, digits: 1, lengths: []int{13, 16, 19}},
+	{brand: "mastercard", low: 51, high: 55, digits: 2, lengths: []int{16}},
+	{brand: "mastercard", low: 2221, high: 2720, digits: 4, lengths: []int{16}},
+	{brand: "amex", low: 34, high: 34, digits: 2, lengths: []int{15}},
+	{brand: "amex", low: 37, high: 37, digits: 2, lengths: []int{15}},
+	{brand: "discover", low: 6011, high: 6011, digits: 4, lengths: []int{16, 17, 18, 19}},
+	{brand: "discover", low: 644, high: 649, digits: 3, lengths: []int{16, 17, 18, 19}},
+	{brand: "discover", low: 65, high: 65, digits: 2, lengths: []int{16, 17, 18, 19}},
+	{brand: "jcb", low: 3528, high: 3589, digits: 4, lengths: []int{16, 17, 18, 19}},
+	{brand: "unionpay", low: 62, high: 62, digits: 2, lengths: []int{16, 17, 18, 19}},
+}
+
+// cardKeywordRegex finds the words that label a payment card number.
+var cardKeywordRegex = regexp.MustCompile(`(?i)\b(card|credit|debit|visa|mastercard|amex|american\s+express|discover|cc|pan|cardholder)\b`)
+
+// cardKeywordWindow is how far before and after a card number keywords are looked for.
+const cardKeywordWindow = 40
+
+// cardDetector reports payment card numbers (PANs) that pass the Luhn check and
+// fall in a known issuer range, with the brand as the detection's Subtype.
+type cardDetector struct {
+	confidence float64
+}
+
+func (c cardDetector) Type() string { return "credit_card" }
+
+func (c cardDetector) ContextRules() ContextRules {
+	return ContextRules{Positive: cardKeywordRegex, Negative: sampleDataRegex, Window: cardKeywordWindow}
+}
+
+func (c cardDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+
+	for _, loc := range cardRegex.FindAllStringIndex(line, -1) {
+		match := line[loc[0]:loc[1]]
+		end, brand := cardPrefix(match)
+		if end == 0 {
+			continue
+		}
+		match = match[:end]
+
+		detections = append(detections, ReadFunctions.PIIDetection{
+			Type:          c.Type(),
+			Subtype:       brand,
+			Value:         match,
+			RedactedValue: redactPAN(match),
+			StartOffset:   loc[0],
+			EndOffset:     loc[0] + end,
+			Confidence:    c.confidence,
+		})
+	}
+
+	return detections
+}
+
+// cardPrefix finds the longest run of whole digit groups at the start of match
+// that is a valid card number, so a PAN followed by other digits ("4111 1111 1111
+// 1111 2025") is still found. It returns the length of that prefix and the brand,
+// or 0 when there is none. Mixed separators are rejected.
+func cardPrefix(match string) (int, string) {
+	if strings.Contains(match, " ") && strings.Contains(match, "-") {
+		return 0, ""
+	}
+
+	ends := []int{len(match)}
+	for i := len(match) - 1; i > 0; i-- {
+		if match[i] == ' ' || match[i] == '-' {
+			ends = append(ends, i)
+		}
+	}
+
+	for _, end := range ends {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(match[:end])
+		if len(digits) < 13 {
+			break
+		}
+		if brand := cardBrand(digits); brand != "" && luhnValid(digits) {
+			return end, brand
+		}
+	}
+	return 0, ""
+}
+
+// cardBrand returns the brand whose issuer range and lengths fit digits, or "".
+func cardBrand(digits string) string {
+	for _, issuer := range cardIssuers {
+		if len(digits) < issuer.digits {
+			continue
+		}
+		prefix, err := strconv.Atoi(digits[:issuer.digits])
+		if err != nil || prefix < issuer.low || prefix > issuer.high {
+			continue
+		}
+		for _, n := range issuer.lengths {
+			if len(digits) == n {
+				return issuer.brand
+			}
+		}
+	}
+	return ""
+}
+
+// luhnValid reports whether digits passes the Luhn (mod 10) checksum.
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
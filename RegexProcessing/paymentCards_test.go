@@ -0,0 +1,55 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestCardDetector(t *testing.T) {
+	testCases := []struct {
+		input    string
+		value    string
+		brand    string
+		redacted string
+		comment  string
+	}{
+		{"Card 4111 1111 1111 1111 exp 12/27", "4111 1111 1111 1111", "visa", "4111 11XX XXXX 1111", "Visa with spaces"},
+		{"4222222222222", "4222222222222", "visa", "422222XXX2222", "13 digit Visa"},
+		{"mc 2223-0000-4841-0010", "2223-0000-4841-0010", "mastercard", "2223-00XX-XXXX-0010", "Mastercard 2-series"},
+		{"5555555555554444", "5555555555554444", "mastercard", "555555XXXXXX4444", "Mastercard 5-series"},
+		{"amex 3782 822463 10005", "3782 822463 10005", "amex", "3782 82XXXX X0005", "Amex 4-6-5 grouping"},
+		{"6011111111111117", "6011111111111117", "discover", "601111XXXXXX1117", "Discover"},
+		{"6221260000000000", "6221260000000000", "discover", "622126XXXXXX0000", "Discover range inside UnionPay 62"},
+		{"3530111333300000", "3530111333300000", "jcb", "353011XXXXXX0000", "JCB"},
+		{"6200000000000005", "6200000000000005", "unionpay", "620000XXXXXX0005", "UnionPay"},
+		{"5555 5555 5555 4444 2025", "5555 5555 5555 4444", "mastercard", "5555 55XX XXXX 4444", "Trailing digits trimmed"},
+		{"4111111111111112", "", "", "", "Fails Luhn"},
+		{"4111 1111-1111 1111", "", "", "", "Mixed separators"},
+		{"9111111111111111", "", "", "", "Unknown issuer"},
+		{"378282246310005", "378282246310005", "amex", "378282XXXXX0005", "Amex without separators"},
+		{"37828224631000", "", "", "", "Amex with wrong length"},
+	}
+
+	detector := cardDetector{confidence: 0.9}
+	for _, tt := range testCases {
+		detections := detector.Detect(tt.input)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("Detect(%q) = %q; want no match (%s)", tt.input, detections[0].Value, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("Detect(%q) returned %d detections; want 1 (%s)", tt.input, len(detections), tt.comment)
+			continue
+		}
+
+		d := detections[0]
+		if d.Value != tt.value || d.Subtype != tt.brand || d.RedactedValue != tt.redacted {
+			t.Errorf("Detect(%q) = %q/%s/%q; want %q/%s/%q (%s)",
+				tt.input, d.Value, d.Subtype, d.RedactedValue, tt.value, tt.brand, tt.redacted, tt.comment)
+		}
+		if tt.input[d.StartOffset:d.EndOffset] != d.Value {
+			t.Errorf("Detect(%q) offsets [%d:%d] don't cover %q", tt.input, d.StartOffset, d.EndOffset, d.Value)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"strings"
+
+	"goScan/ReadFunctions"
+)
+
+// phoneExtension matches an extension after a phone number: "ext 123", "x456", "#7".
+const phoneExtension = `(?:\s*,?\s*(?i:ext\.?|extension|x|#)\s*(\d{1,6})\b)?`
+
+var (
+	// phoneRegex matches North American numbers, with an optional 1 country code, as
+	// area code, exchange, line number and extension in groups 1-4.
+	phoneRegex = regexp.MustCompile(`(?:\b1[-.\s]?\(?|\(|\b)([0-9]{3})\)?[-.\s]?([0-9]{3})[-.\s]?([0-9]{4})\b` + phoneExtension)
+
+	// intlPhoneRegex matches numbers written in international format: a plus sign and
+	// groups of digits, where the national trunk prefix may appear as "(0)".
+	intlPhoneRegex = regexp.MustCompile(`\+\d+(?:[-. ]?\(?\d+\)?)*`)
+
+	// extensionRegex matches an extension directly after an international number.
+	extensionRegex = regexp.MustCompile(`^` + phoneExtension)
+
+	digitRunRegex = regexp.MustCompile(`\d+`)
+
+	// phoneKeywordRegex finds the words that label a phone number, and
+	// phoneNegativeRegex those that label the account and order numbers it can be
+	// mistaken for.
+	phoneKeywordRegex  = regexp.MustCompile(`(?i)\b(phone|ph|tel|telephone|call|mobile|cell|fax|contact|text|sms|whatsapp)\b`)
+	phoneNegativeRegex = regexp.MustCompile(`(?i)\b(order|invoice|account|acct|ref|reference|tracking|serial|isbn|sku|part|transaction|confirmation)\b`)
+)
+
+// phoneKeywordWindow is how far before and after a number phone keywords are looked for.
+const phoneKeywordWindow = 30
+
+// callingCodes maps ITU country calling codes to the shortest and longest national
+// significant number used under them. Calling codes form a prefix code, so at most
+// one of a number's first one, two or three digits is a key.
+var callingCodes = map[string][2]int{
+	"1": {10, 10}, "7": {10, 10},
+	"20": {9, 10}, "27": {9, 9}, "30": {10, 10}, "31": {9, 9}, "32": {8, 9}, "33": {9, 9}, "34": {9, 9},
+	"36": {8, 9}, "39": {6, 11}, "40": {9, 9}, "41": {9, 9}, "43": {4, 13}, "44": {9, 10}, "45": {8, 8},
+	"46": {7, 10}, "47": {8, 8}, "48": {9, 9}, "49": {6, 13}, "51": {8, 9}, "52": {10, 10}, "53": {8, 8},
+	"54": {10, 11}, "55": {10, 11}, "56": {9, 9}, "57": {10, 10}, "58": {10, 10}, "60": {8, 10}, "61": {9, 9},
+	"62": {8, 12}, "63": {8, 10}, "64": {8, 10}, "65": {8, 8}, "66": {8, 9}, "81": {9, 10}, "82": {8, 10},
+	"84": {9, 10}, "86": {10, 11}, "90": {10, 10}, "91": {10, 10}, "92": {9, 10}, "93": {9, 9}, "94": {9, 9},
+	"95": {8, 10}, "98": {10, 10},
+	"212": {9, 9}, "213": {8, 9}, "216": {8, 8}, "234": {8, 10}, "254": {9, 9}, "255": {9, 9}, "256": {9, 9},
+	"263": {9, 9}, "351": {9, 9}, "352": {4, 11}, "353": {7, 9}, "354": {7, 7}, "358": {5, 12}, "359": {8, 9},
+	"370": {8, 8}, "371": {8, 8}, "372": {7, 8}, "380": {9, 9}, "385": {8, 9}, "386": {8, 8}, "420": {9, 9},
+	"421": {9, 9}, "852": {8, 8}, "853": {8, 8}, "880": {10, 10}, "886": {8, 9}, "966": {9, 9}, "971": {8, 9},
+	"972": {8, 9}, "974": {8, 8}, "977": {8, 10},
+}
+
+// maxE164Digits is the most digits an E.164 number can have, country code included.
+const maxE164Digits = 15
+
+// phoneDetector reports phone numbers with their E.164 form in Normalized and any
+// extension in Extension. North American numbers are recognised with or without a
+// country code and checked against the NANP's area code and exchange rules; other
+// numbers must be written with a plus sign and are checked against the length rules
+// for their calling code. Ten or eleven digits with no separators are reported with
+// bareConfidence, since most such numbers are something else, unless context
+// scoring finds a phone keyword beside them.
+type phoneDetector struct {
+	confidence     float64
+	bareConfidence float64
+}
+
+func (p phoneDetector) Type() string { return "phone" }
+
+func (p phoneDetector) ContextRules() ContextRules {
+	return ContextRules{Positive: phoneKeywordRegex, Negative: phoneNegativeRegex, Window: phoneKeywordWindow}
+}
+
+func (p phoneDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+	var covered [][2]int
+
+	for _, loc := range intlPhoneRegex.FindAllStringIndex(line, -1) {
+		e164, length, ok := parseInternational(line[loc[0]:loc[1]])
+		if !ok {
+			continue
+		}
+		numberEnd := loc[0] + length
+		end, extension := numberEnd, ""
+		if ext := extensionRegex.FindStringSubmatchIndex(line[numberEnd:]); ext != nil && ext[2] >= 0 {
+			end, extension = numberEnd+ext[1], line[numberEnd+ext[2]:numberEnd+ext[3]]
+		}
+
+		covered = append(covered, [2]int{loc[0], end})
+		detections = append(detections, p.detection(line, loc[0], numberEnd, end, e164, extension, p.confidence))
+	}
+
+	for _, m := range phoneRegex.FindAllStringSubmatchIndex(line, -1) {
+		if overlapsAny(covered, m[0], m[1]) {
+			continue
+		}
+		area, exchange, number := line[m[2]:m[3]], line[m[4]:m[5]], line[m[6]:m[7]]
+		if !nanpValid(area, exchange, number) {
+			continue
+		}
+		numberEnd, extension := m[7], ""
+		if m[8] >= 0 {
+			extension = line[m[8]:m[9]]
+		}
+
+		confidence := p.confidence
+		if digits := line[m[0]:numberEnd]; len(digitsOnly(digits)) == len(digits) {
+			confidence = p.bareConfidence
+		}
+		detections = append(detections, p.detection(line, m[0], numberEnd, m[1], "+1"+area+exchange+number, extension, confidence))
+	}
+
+	return detections
+}
+
+// detection reports line[start:end], where the number itself ends at numberEnd and
+// any extension follows. Only the last four digits of the number are left unmasked,
+// so an extension is masked but "ext" is kept.
+func (p phoneDetector) detection(line string, start, numberEnd, end int, e164, extension string, confidence float64) ReadFunctions.PIIDetection {
+	return ReadFunctions.PIIDetection{
+		Type:          p.Type(),
+		Value:         line[start:end],
+		RedactedValue: redactKeepLast4(line[start:numberEnd]) + maskDigits(line[numberEnd:end]),
+		StartOffset:   start,
+		EndOffset:     end,
+		Confidence:    confidence,
+		Normalized:    e164,
+		Extension:     extension,
+	}
+}
+
+// parseInternational finds the longest leading part of match, a plus sign and digit
+// groups, that is a valid number for its calling code. It returns the number in
+// E.164 form and the length of match it covers, so trailing groups that belong to
+// something else are left out.
+func parseInternational(match string) (e164 string, length int, ok bool) {
+	var runs [][]int
+	for _, run := range digitRunRegex.FindAllStringIndex(match, -1) {
+		// (0) is the trunk prefix dialled within the country, not part of the number.
+		if match[run[0]:run[1]] == "0" && run[0] > 0 && match[run[0]-1] == '(' && run[1] < len(match) && match[run[1]] == ')' {
+			continue
+		}
+		runs = append(runs, run)
+	}
+
+	for n := len(runs); n > 0; n-- {
+		var digits strings.Builder
+		for _, run := range runs[:n] {
+			digits.WriteString(match[run[0]:run[1]])
+		}
+		if number := digits.String(); internationalValid(number) {
+			end := runs[n-1][1]
+			if end < len(match) && match[end] == ')' {
+				end++
+			}
+			return "+" + number, end, true
+		}
+	}
+	return "", 0, false
+}
+
+// internationalValid checks a number's digits, country code first, against the
+// national number lengths for its calling code and, for +1, the NANP rules.
+func internationalValid(digits string) bool {
+	if len(digits) > maxE164Digits {
+		return false
+	}
+	for n := 1; n <= 3 && n < len(digits); n++ {
+		lengths, ok := callingCodes[digits[:n]]
+		if !ok {
+			continue
+		}
+		national := digits[n:]
+		if len(national) < lengths[0] || len(national) > lengths[1] {
+			return false
+		}
+		return n > 1 || digits[0] != '1' || nanpValid(national[:3], national[3:6], national[6:])
+	}
+	return false
+}
+
+// nanpValid checks a North American number's parts. Area codes and exchanges start
+// with 2-9 and are never N11, which are service codes such as 411 and 911, and
+// 555-0100 to 555-0199 are reserved for fiction.
+func nanpValid(area, exchange, number string) bool {
+	for _, code := range []string{area, exchange} {
+		if code[0] < '2' || code[1:] == "11" {
+			return false
+		}
+	}
+	return !(exchange == "555" && strings.HasPrefix(number, "01"))
+}
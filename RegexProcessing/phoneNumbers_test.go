@@ -0,0 +1,59 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestPhoneDetector(t *testing.T) {
+	testCases := []struct {
+		text       string
+		value      string // "" when nothing should be found
+		normalized string
+		extension  string
+		redacted   string
+		confidence float64
+		comment    string
+	}{
+		{"Call (202) 456-1414", "(202) 456-1414", "+12024561414", "", "(XXX) XXX-1414", 0.7, "NANP with parentheses"},
+		{"1-800-555-1234", "1-800-555-1234", "+18005551234", "", "X-XXX-XXX-1234", 0.7, "Toll-free with country code"},
+		{"+1 (212) 736-5000", "+1 (212) 736-5000", "+12127365000", "", "+X (XXX) XXX-5000", 0.7, "International format +1"},
+		{"2024561414", "2024561414", "+12024561414", "", "XXXXXX1414", 0.4, "Bare ten digits"},
+		{"212-736-5000 ext 123", "212-736-5000 ext 123", "+12127365000", "123", "XXX-XXX-5000 ext XXX", 0.7, "Extension"},
+		{"(212) 736-5000 x45", "(212) 736-5000 x45", "+12127365000", "45", "(XXX) XXX-5000 xXX", 0.7, "x extension"},
+		{"+44 20 7946 0958", "+44 20 7946 0958", "+442079460958", "", "+XX XX XXXX 0958", 0.7, "UK landline"},
+		{"+44 (0)20 7946 0958", "+44 (0)20 7946 0958", "+442079460958", "", "+XX (X)XX XXXX 0958", 0.7, "Trunk prefix dropped"},
+		{"+49 30 901820", "+49 30 901820", "+4930901820", "", "+XX XX XX1820", 0.7, "German number"},
+		{"+33 1 42 68 53 00, ext. 7", "+33 1 42 68 53 00, ext. 7", "+33142685300", "7", "+XX X XX XX 53 00, ext. X", 0.7, "French number with extension"},
+		{"+91 98765 43210 2 rooms", "+91 98765 43210", "+919876543210", "", "+XX XXXXX X3210", 0.7, "Trailing group left out"},
+		{"+44 20 7946", "", "", "", "", 0, "Too short for +44"},
+		{"+999 1234 5678", "", "", "", "", 0, "Unassigned calling code"},
+		{"555-123-4567", "", "", "", "", 0, "Exchange starting with 1"},
+		{"311-555-1234", "", "", "", "", 0, "N11 area code"},
+		{"202-911-1234", "", "", "", "", 0, "N11 exchange"},
+		{"(202) 555-0143", "", "", "", "", 0, "555-01xx fiction range"},
+		{"+1 202 555 0143", "", "", "", "", 0, "Fiction range in international format"},
+		{"123-45-6789", "", "", "", "", 0, "SSN"},
+	}
+
+	d := phoneDetector{confidence: 0.7, bareConfidence: 0.4}
+	for _, tt := range testCases {
+		detections := d.Detect(tt.text)
+		if tt.value == "" {
+			if len(detections) != 0 {
+				t.Errorf("Detect(%q) = %+v; want nothing (%s)", tt.text, detections, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("Detect(%q) returned %d detections; want 1 (%s)", tt.text, len(detections), tt.comment)
+			continue
+		}
+		got := detections[0]
+		if got.Value != tt.value || got.Normalized != tt.normalized || got.Extension != tt.extension ||
+			got.RedactedValue != tt.redacted || got.Confidence != tt.confidence {
+			t.Errorf("Detect(%q) = %q %q ext %q redacted %q confidence %.2f; want %q %q ext %q redacted %q confidence %.2f (%s)",
+				tt.text, got.Value, got.Normalized, got.Extension, got.RedactedValue, got.Confidence,
+				tt.value, tt.normalized, tt.extension, tt.redacted, tt.confidence, tt.comment)
+		}
+	}
+}
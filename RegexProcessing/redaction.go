@@ -0,0 +1,95 @@
+package RegexProcessing
+
+import (
+	"strings"
+)
+
+// redactAll masks every letter and digit in value, leaving separators in place.
+func redactAll(value string) string {
+	return maskAlnum(value, 0)
+}
+
+// redactKeepLast4 masks all but the last four digits, e.g. "123-45-6789" -> "XXX-XX-6789".
+func redactKeepLast4(value string) string {
+	return maskAlnum(value, 4)
+}
+
+// redactPAN keeps the first six and last four digits of a card number, as PCI DSS
+// allows, e.g. "4111 1111 1111 1111" -> "4111 11XX XXXX 1111".
+func redactPAN(value string) string {
+	total := 0
+	for i := 0; i < len(value); i++ {
+		if isDigit(value[i]) {
+			total++
+		}
+	}
+
+	out := []byte(value)
+	seen := 0
+	for i := range out {
+		if !isDigit(out[i]) {
+			continue
+		}
+		seen++
+		if seen > 6 && seen <= total-4 {
+			out[i] = 'X'
+		}
+	}
+	return string(out)
+}
+
+// redactEmail keeps the first character of the local part and the domain,
+// e.g. "john.doe@example.com" -> "j*******@example.com".
+func redactEmail(value string) string {
+	at := strings.LastIndex(value, "@")
+	if at <= 0 {
+		return redactAll(value)
+	}
+	return value[:1] + strings.Repeat("*", at-1) + value[at:]
+}
+
+// redactSecret keeps the first four characters of a credential, which usually name
+// its kind ("AKIA", "ghp_", "eyJh"), and masks the rest, e.g. "ghp_abc..." ->
+// "ghp_*****...". Values of eight characters or fewer are masked completely.
+func redactSecret(value string) string {
+	keep := 4
+	if len(value) <= 8 {
+		keep = 0
+	}
+	return value[:keep] + strings.Repeat("*", len(value)-keep)
+}
+
+// maskDigits replaces every digit with 'X', leaving letters and separators in place.
+func maskDigits(value string) string {
+	out := []byte(value)
+	for i := range out {
+		if isDigit(out[i]) {
+			out[i] = 'X'
+		}
+	}
+	return string(out)
+}
+
+// maskAlnum replaces letters and digits with 'X', leaving the last keep of them visible.
+func maskAlnum(value string, keep int) string {
+	out := []byte(value)
+	for i := len(out) - 1; i >= 0; i-- {
+		if !isAlnum(out[i]) {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		out[i] = 'X'
+	}
+	return string(out)
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isAlnum(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
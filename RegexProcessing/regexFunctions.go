@@ -1,18 +1,150 @@
 package RegexProcessing
 
 import (
-	"goScan/ReadFunctions"
+	"errors"
 	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"goScan/ReadFunctions"
 )
 
 var (
 	emailRegex = regexp.MustCompile(`\b[A-Za-z0-9](?:[A-Za-z0-9._%+-]*[A-Za-z0-9])?@[A-Za-z0-9](?:[A-Za-z0-9.-]*[A-Za-z0-9])?\.[A-Za-z]{2,6}\b`)
 	dobRegex   = regexp.MustCompile(`\b(0[1-9]|1[0-2])[-/](0[1-9]|[12][0-9]|3[01])[-/](\d{2}|\d{4})\b`)
 	ssnRegex   = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b|\b\d{3}\s\d{2}\s\d{4}\b|\b\d{9}\b`)
-	phoneRegex = regexp.MustCompile(`\b\(?[0-9]{3}\)?[-.\s]?[0-9]{3}[-.\s]?[0-9]{4}\b|\b[0-9]{10,11}\b`)
 	nameRegex  = regexp.MustCompile(`\b[A-Z][a-zA-Z'-]{1,}(?:\s[A-Z][a-zA-Z'-]{1,})*\b`)
 )
 
-func checkStrings(line string) (ReadFunctions.PIIDetection, error) {
+// contextWindow is how many bytes either side of a match are kept in PIIDetection.Context.
+const contextWindow = 30
+
+// ErrNoDetectors is returned when a scan is attempted with an empty registry.
+var ErrNoDetectors = errors.New("no detectors registered")
+
+func init() {
+	mustRegister(regexDetector{detectionType: "email", pattern: emailRegex, confidence: 0.9, redact: redactEmail,
+		negative: sampleDataRegex})
+	mustRegister(ssnDetector{confidence: 0.8, bareConfidence: 0.4})
+	mustRegister(phoneDetector{confidence: 0.7, bareConfidence: 0.4})
+	mustRegister(dateDetector{confidence: 0.4, keywordConfidence: 0.85})
+	mustRegister(nameDetector{})
+	mustRegister(cardDetector{confidence: 0.9})
+	mustRegister(addressDetector{partConfidence: 0.6, fullConfidence: 0.9})
+	mustRegister(regexDetector{detectionType: "npi", pattern: npiRegex, confidence: 0.5, validate: npiValid,
+		redact: redactKeepLast4, keyword: npiKeywordRegex, keywordConfidence: 0.9, negative: referenceNumberRegex})
+	mustRegister(regexDetector{detectionType: "dea", pattern: deaRegex, confidence: 0.8, validate: deaValid,
+		redact: redactKeepLast4, keyword: deaKeywordRegex, keywordConfidence: 0.95, negative: sampleDataRegex})
+	mustRegister(regexDetector{detectionType: "mbi", pattern: mbiRegex, confidence: 0.85, validate: mbiValid,
+		redact: redactKeepLast4, keyword: mbiKeywordRegex, keywordConfidence: 0.95, negative: sampleDataRegex})
+	mustRegister(labelledIDDetector{detectionType: "mrn", label: mrnLabelRegex, confidence: 0.85})
+	mustRegister(labelledIDDetector{detectionType: "health_plan", label: healthPlanLabelRegex, confidence: 0.8})
+	mustRegister(codeDetector{detectionType: "icd10", pattern: icd10Regex, keyword: icd10KeywordRegex,
+		weak: icd10Weak, confidence: 0.45, keywordConfidence: 0.9, negative: referenceNumberRegex})
+	mustRegister(codeDetector{detectionType: "cpt", pattern: cptRegex, keyword: cptKeywordRegex,
+		validate: cptValid, weak: cptWeak, confidence: 0.4, keywordConfidence: 0.85, negative: referenceNumberRegex})
+	mustRegister(codeDetector{detectionType: "ndc", pattern: ndcRegex, keyword: ndcKeywordRegex,
+		validate: ndcValid, confidence: 0.6, keywordConfidence: 0.9, negative: referenceNumberRegex})
+	mustRegister(codeDetector{detectionType: "loinc", pattern: loincRegex, keyword: loincKeywordRegex,
+		validate: loincValid, weak: always, keywordConfidence: 0.85, negative: referenceNumberRegex})
+	mustRegister(secretDetector{})
+	mustRegister(regexDetector{detectionType: "iban", pattern: ibanRegex, confidence: 0.9, validate: ibanValid,
+		redact: redactKeepLast4, keyword: ibanKeywordRegex, keywordConfidence: 0.95, negative: sampleDataRegex})
+	mustRegister(codeDetector{detectionType: "aba_routing", pattern: routingRegex, keyword: routingKeywordRegex,
+		validate: routingValid, weak: always, redact: redactKeepLast4, keywordConfidence: 0.9, negative: referenceNumberRegex})
+	mustRegister(codeDetector{detectionType: "swift_bic", pattern: bicRegex, keyword: bicKeywordRegex,
+		validate: bicValid, weak: always, keywordConfidence: 0.85, negative: sampleDataRegex})
+	mustRegister(codeDetector{detectionType: "ein", pattern: einRegex, keyword: einKeywordRegex,
+		validate: einValid, weak: einWeak, redact: redactKeepLast4, confidence: 0.5, keywordConfidence: 0.9,
+		negative: referenceNumberRegex})
+	mustRegister(labelledIDDetector{detectionType: "bank_account", label: accountLabelRegex, confidence: 0.75})
+	mustRegister(ipDetector{confidence: 0.8, privateConfidence: 0.5})
+	mustRegister(regexDetector{detectionType: "mac_address", pattern: macRegex, confidence: 0.8, validate: macValid,
+		negative: sampleDataRegex})
+	mustRegister(codeDetector{detectionType: "imei", pattern: imeiRegex, keyword: imeiKeywordRegex,
+		validate: imeiValid, weak: unseparated, redact: redactKeepLast4, confidence: 0.6, keywordConfidence: 0.9,
+		negative: sampleDataRegex})
+	mustRegister(regexDetector{detectionType: "vin", pattern: vinRegex, confidence: 0.85, validate: vinValid,
+		redact: redactKeepLast4, keyword: vinKeywordRegex, keywordConfidence: 0.95, negative: sampleDataRegex})
+	mustRegister(urlDetector{profileConfidence: 0.8, pageConfidence: 0.6})
+	mustRegister(keywordDetector{})
+}
+
+// ScanSegment is the ReadFunctions.Scanner backed by the detector registry.
+func ScanSegment(seg ReadFunctions.Segment) ([]ReadFunctions.PIIDetection, error) {
+	detections, err := checkField(seg.Text, seg.Field, seg.LineNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range detections {
+		detections[i].StartOffset += seg.Offset
+		detections[i].EndOffset += seg.Offset
+		detections[i].Location = seg.Location
+	}
+	applyFieldContext(detections, seg.Field)
+	return detections, nil
+}
+
+// checkStrings runs every registered detector over line and returns the detections
+// ordered by position, with offsets relative to the start of line.
+func checkStrings(line string, lineNumber int) ([]ReadFunctions.PIIDetection, error) {
+	return checkField(line, "", lineNumber)
+}
+
+// checkField is checkStrings for a value stored under field, a column header or key,
+// which FieldDetectors may recognise the whole value by.
+func checkField(line, field string, lineNumber int) ([]ReadFunctions.PIIDetection, error) {
+	detectors := Detectors()
+	if len(detectors) == 0 {
+		return nil, ErrNoDetectors
+	}
+
+	var detections []ReadFunctions.PIIDetection
+	for _, d := range detectors {
+		found := d.Detect(line)
+		if fd, ok := d.(FieldDetector); ok && field != "" && len(found) == 0 {
+			found = fd.DetectField(field, line)
+		}
+		cd, scored := d.(ContextDetector)
+		for _, detection := range found {
+			if scored {
+				detection.Confidence = scoreContext(detection.Confidence, cd.ContextRules(), line, detection.StartOffset, detection.EndOffset)
+			}
+			detection.LineNumber = lineNumber
+			if detection.Context == "" {
+				detection.Context = surroundingText(line, detection.StartOffset, detection.EndOffset)
+			}
+			if detection.DetectionMethod == "" {
+				detection.DetectionMethod = "regex"
+			}
+			detections = append(detections, detection)
+		}
+	}
+
+	sort.SliceStable(detections, func(i, j int) bool {
+		return detections[i].StartOffset < detections[j].StartOffset
+	})
+
+	return detections, nil
+}
+
+// surroundingText returns the match with up to contextWindow bytes either side,
+// widened as needed so a multibyte character is never split.
+func surroundingText(line string, start, end int) string {
+	from := max(start-contextWindow, 0)
+	for from > 0 && !utf8.RuneStart(line[from]) {
+		from--
+	}
+	to := min(end+contextWindow, len(line))
+	for to < len(line) && !utf8.RuneStart(line[to]) {
+		to++
+	}
+	return line[from:to]
+}
 
+// keywordNear reports whether re matches within window bytes either side of
+// line[start:end].
+func keywordNear(re *regexp.Regexp, line string, start, end, window int) bool {
+	return re.MatchString(line[max(start-window, 0):min(end+window, len(line))])
 }
@@ -0,0 +1,261 @@
+package RegexProcessing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"regexp"
+	"strings"
+
+	"goScan/ReadFunctions"
+)
+
+// secretPattern is one kind of credential the secret detector looks for. When the
+// pattern has a capture group, only group 1 is reported, so "password=hunter2" is
+// reported as "hunter2".
+type secretPattern struct {
+	subtype    string
+	pattern    *regexp.Regexp
+	validate   func(match string) bool
+	severity   string
+	confidence float64
+}
+
+// secretPatterns are tried in order; a later pattern never reports text an earlier
+// one already covered, so the generic assignment pattern comes last.
+var secretPatterns = []secretPattern{
+	{
+		subtype:    "private_key",
+		pattern:    pemBlockRegex(`(?:RSA |EC |DSA |OPENSSH |PGP )?PRIVATE KEY(?: BLOCK)?`),
+		severity:   "critical",
+		confidence: 0.99,
+	},
+	{
+		subtype:    "encrypted_private_key",
+		pattern:    pemBlockRegex(`ENCRYPTED PRIVATE KEY`),
+		severity:   "high",
+		confidence: 0.99,
+	},
+	{
+		subtype:    "aws_access_key",
+		pattern:    regexp.MustCompile(`\b(?:AKIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|APKA|AROA|ASCA)[A-Z2-7]{16}\b`),
+		severity:   "critical",
+		confidence: 0.95,
+	},
+	{
+		subtype:    "aws_session_key",
+		pattern:    regexp.MustCompile(`\bASIA[A-Z2-7]{16}\b`),
+		severity:   "high",
+		confidence: 0.95,
+	},
+	{
+		subtype:    "aws_secret_key",
+		pattern:    regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|private).{0,20}?[\s"':=]+([A-Za-z0-9/+]{40})\b`),
+		severity:   "critical",
+		confidence: 0.9,
+	},
+	{
+		subtype:    "gcp_service_account",
+		pattern:    regexp.MustCompile(`"private_key_id"\s*:\s*"([0-9a-f]{40})"`),
+		severity:   "critical",
+		confidence: 0.95,
+	},
+	{
+		subtype:    "github_token",
+		pattern:    regexp.MustCompile(`\b(?:(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`),
+		severity:   "critical",
+		confidence: 0.99,
+	},
+	{
+		subtype:    "slack_token",
+		pattern:    regexp.MustCompile(`\bxox[abeoprs]-[A-Za-z0-9]+(?:-[A-Za-z0-9]+){1,4}\b`),
+		severity:   "high",
+		confidence: 0.95,
+	},
+	{
+		subtype:    "slack_webhook",
+		pattern:    regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+`),
+		severity:   "medium",
+		confidence: 0.95,
+	},
+	{
+		subtype:    "jwt",
+		pattern:    regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`),
+		validate:   jwtValid,
+		severity:   "high",
+		confidence: 0.9,
+	},
+	{
+		subtype: "connection_string",
+		pattern: regexp.MustCompile(`\b(?:postgres(?:ql)?|mysql|mariadb|mongodb(?:\+srv)?|rediss?|amqps?|mssql|sqlserver|oracle)` +
+			`://[^\s:/@'"]+:([^\s@/'"]+)@[^\s'"]+`),
+		validate:   realPassword,
+		severity:   "critical",
+		confidence: 0.9,
+	},
+	{
+		subtype:    "connection_string",
+		pattern:    regexp.MustCompile(`(?i)\b(?:server|data\s+source|host)\s*=[^;]+;.*?\b(?:password|pwd)\s*=\s*([^;'"\s]+)`),
+		validate:   realPassword,
+		severity:   "critical",
+		confidence: 0.85,
+	},
+	{
+		subtype: "generic",
+		pattern: regexp.MustCompile(`(?i)\b[a-z0-9_.-]*(?:secret|token|api[_-]?key|access[_-]?key|passwd|password|pwd|credential|private[_-]?key)` +
+			`[a-z0-9_.-]*["']?\s*[:=]\s*["']?([A-Za-z0-9+/=_\-!@#$%^&*.~]{16,})`),
+		validate:   highEntropy,
+		severity:   "medium",
+		confidence: 0.6,
+	},
+}
+
+// pemBlockRegex matches a PEM block whose label matches label, from its BEGIN line
+// through its END line, so the key body is reported with it. When the END line isn't
+// in the text, as when a file is scanned a line at a time, only the BEGIN line matches.
+func pemBlockRegex(label string) *regexp.Regexp {
+	return regexp.MustCompile(`-----BEGIN ` + label + `-----(?:[A-Za-z0-9+/=\s:,.-]*?-----END ` + label + `-----)?`)
+}
+
+// minSecretEntropy is the Shannon entropy, in bits per character, a value needs to be
+// reported as a generic secret. Random base64 scores around 5-6 and English words
+// around 3-4.
+const minSecretEntropy = 3.5
+
+// placeholderRegex matches values that stand in for a secret rather than being one.
+var placeholderRegex = regexp.MustCompile(`(?i)^(?:\$\{.*\}|\$\(.*\)|\{\{.*\}\}|<.*>|%.*%|\*+|x+|.*(?:changeme|example|placeholder|your[_-]?).*)$`)
+
+// secretDetector reports leaked credentials: private keys, cloud and API tokens,
+// connection strings with passwords, and high-entropy values assigned to names such
+// as "api_key" or "password". Each detection has the kind of credential as its
+// Subtype and a Severity for how much access it is likely to give.
+type secretDetector struct{}
+
+func (s secretDetector) Type() string { return "secret" }
+
+func (s secretDetector) ContextRules() ContextRules {
+	return ContextRules{Negative: sampleDataRegex, Window: keywordWindow}
+}
+
+func (s secretDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+	var covered [][2]int
+
+	for _, p := range secretPatterns {
+		for _, loc := range p.pattern.FindAllStringSubmatchIndex(line, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) > 2 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			if overlapsAny(covered, loc[0], loc[1]) {
+				continue
+			}
+			match := line[start:end]
+			if p.validate != nil && !p.validate(match) {
+				continue
+			}
+			covered = append(covered, [2]int{loc[0], loc[1]})
+			detections = append(detections, s.detection(p, match, start, end))
+		}
+	}
+
+	return detections
+}
+
+// DetectField recognises secrets stored under a key or column, such as the
+// private_key and private_key_id of a GCP service account file or a high-entropy "api_key" value.
+func (s secretDetector) DetectField(field, value string) []ReadFunctions.PIIDetection {
+	key := field[strings.LastIndexByte(field, '.')+1:]
+	trimmed := strings.TrimSpace(value)
+	start := strings.Index(value, trimmed)
+
+	for _, p := range secretPatterns {
+		switch {
+		case p.subtype == "gcp_service_account" && key == "private_key_id" && isHex(trimmed, 40),
+			p.subtype == "private_key" && key == "private_key" && len(trimmed) >= 64 && highEntropy(trimmed),
+			p.subtype == "generic" && fieldNamesType(field, s.Type()) && !strings.ContainsAny(trimmed, " \t") &&
+				len(trimmed) >= 16 && highEntropy(trimmed):
+			return []ReadFunctions.PIIDetection{s.detection(p, trimmed, start, start+len(trimmed))}
+		}
+	}
+	return nil
+}
+
+func (s secretDetector) detection(p secretPattern, match string, start, end int) ReadFunctions.PIIDetection {
+	return ReadFunctions.PIIDetection{
+		Type:          s.Type(),
+		Subtype:       p.subtype,
+		Value:         match,
+		RedactedValue: redactSecret(match),
+		StartOffset:   start,
+		EndOffset:     end,
+		Confidence:    p.confidence,
+		Severity:      p.severity,
+	}
+}
+
+// overlapsAny reports whether [start, end) overlaps any of the covered ranges.
+func overlapsAny(covered [][2]int, start, end int) bool {
+	for _, c := range covered {
+		if start < c[1] && c[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// jwtValid checks that a JWT's header decodes to a JSON object with an "alg".
+func jwtValid(token string) bool {
+	header, err := base64.RawURLEncoding.DecodeString(token[:strings.IndexByte(token, '.')])
+	if err != nil {
+		return false
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(header, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["alg"]
+	return ok
+}
+
+// realPassword rejects empty passwords and placeholders such as "${DB_PASSWORD}".
+func realPassword(password string) bool {
+	return password != "" && !placeholderRegex.MatchString(password)
+}
+
+// highEntropy reports whether value looks random enough to be a generated secret.
+func highEntropy(value string) bool {
+	return realPassword(value) && shannonEntropy(value) >= minSecretEntropy
+}
+
+// shannonEntropy returns the entropy of value in bits per byte.
+func shannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(value); i++ {
+		counts[value[i]]++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		if n > 0 {
+			p := float64(n) / float64(len(value))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// isHex reports whether s is n lower case hex digits.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
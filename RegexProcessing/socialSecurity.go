@@ -0,0 +1,94 @@
+package RegexProcessing
+
+import (
+	"regexp"
+	"strings"
+
+	"goScan/ReadFunctions"
+)
+
+// ssnKeywordRegex finds the words that usually label an SSN or ITIN.
+var ssnKeywordRegex = regexp.MustCompile(`(?i)\b(ssn|ss#|ssan|social\s+security|soc\.?\s*sec|itin|taxpayer\s+id|tax\s*id|tin)\b`)
+
+// ssnNegativeRegex finds the words that label other nine digit numbers.
+var ssnNegativeRegex = regexp.MustCompile(`(?i)\b(order|invoice|tracking|serial|part|sku|ticket|confirmation|routing|phone|fax)\b`)
+
+// ssnKeywordWindow is how far before and after a match ssnKeywordRegex is looked for.
+const ssnKeywordWindow = 40
+
+// advertisingSSNs were published in advertising or wallet inserts and are never
+// assigned to anyone; 987-65-4320 to 987-65-4329 are reserved for advertising.
+var advertisingSSNs = map[string]bool{
+	"078051120": true, "219099999": true, "457555462": true,
+	"987654320": true, "987654321": true, "987654322": true, "987654323": true, "987654324": true,
+	"987654325": true, "987654326": true, "987654327": true, "987654328": true, "987654329": true,
+}
+
+// ssnDetector reports Social Security numbers that could have been issued, and
+// ITINs, which share the format, with Subtype "itin". Nine digits with no
+// separators are reported with bareConfidence unless an SSN keyword is nearby,
+// since most such numbers are something else.
+type ssnDetector struct {
+	confidence     float64
+	bareConfidence float64
+}
+
+func (s ssnDetector) Type() string { return "ssn" }
+
+func (s ssnDetector) ContextRules() ContextRules {
+	return ContextRules{Negative: ssnNegativeRegex, Window: ssnKeywordWindow}
+}
+
+func (s ssnDetector) Detect(line string) []ReadFunctions.PIIDetection {
+	var detections []ReadFunctions.PIIDetection
+
+	for _, loc := range ssnRegex.FindAllStringIndex(line, -1) {
+		match := line[loc[0]:loc[1]]
+		digits := strings.NewReplacer("-", "", " ", "").Replace(match)
+		subtype, ok := classifySSN(digits)
+		if !ok {
+			continue
+		}
+
+		confidence := s.confidence
+		if len(match) == len(digits) && !keywordNear(ssnKeywordRegex, line, loc[0], loc[1], ssnKeywordWindow) {
+			confidence = s.bareConfidence
+		}
+
+		detections = append(detections, ReadFunctions.PIIDetection{
+			Type:          s.Type(),
+			Subtype:       subtype,
+			Value:         match,
+			RedactedValue: redactKeepLast4(match),
+			StartOffset:   loc[0],
+			EndOffset:     loc[1],
+			Confidence:    confidence,
+		})
+	}
+
+	return detections
+}
+
+// classifySSN checks nine digits against the SSA's issuance rules. Area 000 and
+// 666, group 00, serial 0000 and advertising numbers are never issued. Areas 900
+// to 999 are reserved for ITINs, which use groups 50-65, 70-88, 90-92 and 94-99;
+// those come back with subtype "itin" and any other 9xx number is rejected.
+func classifySSN(digits string) (subtype string, ok bool) {
+	if len(digits) != 9 {
+		return "", false
+	}
+	area, group, serial := digits[:3], digits[3:5], digits[5:]
+	if area == "000" || area == "666" || group == "00" || serial == "0000" || advertisingSSNs[digits] {
+		return "", false
+	}
+	if area[0] != '9' {
+		return "", true
+	}
+
+	g := int(group[0]-'0')*10 + int(group[1]-'0')
+	switch {
+	case g >= 50 && g <= 65, g >= 70 && g <= 88, g >= 90 && g <= 92, g >= 94 && g <= 99:
+		return "itin", true
+	}
+	return "", false
+}
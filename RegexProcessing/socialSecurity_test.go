@@ -0,0 +1,55 @@
+package RegexProcessing
+
+import (
+	"testing"
+)
+
+func TestSSNDetector(t *testing.T) {
+	testCases := []struct {
+		text       string
+		found      bool
+		subtype    string
+		confidence float64
+		comment    string
+	}{
+		{"123-45-6789", true, "", 0.8, "Separated SSN"},
+		{"123 45 6789", true, "", 0.8, "Space separated SSN"},
+		{"Reference #123456789", true, "", 0.4, "Bare nine digits"},
+		{"SSN: 123456789", true, "", 0.8, "Bare nine digits after keyword"},
+		{"social security number 123456789", true, "", 0.8, "Keyword spelled out"},
+		{"123456789 (ssn)", true, "", 0.8, "Keyword after the number"},
+
+		{"000-12-3456", false, "", 0, "Area 000"},
+		{"666-12-3456", false, "", 0, "Area 666"},
+		{"123-00-4567", false, "", 0, "Group 00"},
+		{"123-45-0000", false, "", 0, "Serial 0000"},
+		{"000-00-0000", false, "", 0, "All zeros"},
+		{"078-05-1120", false, "", 0, "Woolworth wallet card"},
+		{"987-65-4325", false, "", 0, "Reserved for advertising"},
+		{"900-12-3456", false, "", 0, "9xx outside ITIN groups"},
+		{"912-93-4567", false, "", 0, "ITIN group 93 is not used"},
+
+		{"912-70-1234", true, "itin", 0.8, "ITIN"},
+		{"ITIN 912501234", true, "itin", 0.8, "Bare ITIN after keyword"},
+		{"999-99-9999", true, "itin", 0.8, "ITIN at the top of the range"},
+	}
+
+	detector := ssnDetector{confidence: 0.8, bareConfidence: 0.4}
+	for _, tt := range testCases {
+		detections := detector.Detect(tt.text)
+		if !tt.found {
+			if len(detections) != 0 {
+				t.Errorf("Detect(%q) = %q; want no match (%s)", tt.text, detections[0].Value, tt.comment)
+			}
+			continue
+		}
+		if len(detections) != 1 {
+			t.Errorf("Detect(%q) returned %d detections; want 1 (%s)", tt.text, len(detections), tt.comment)
+			continue
+		}
+		if d := detections[0]; d.Subtype != tt.subtype || d.Confidence != tt.confidence {
+			t.Errorf("Detect(%q) = subtype %q confidence %.2f; want %q %.2f (%s)",
+				tt.text, d.Subtype, d.Confidence, tt.subtype, tt.confidence, tt.comment)
+		}
+	}
+}
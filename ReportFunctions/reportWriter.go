@@ -0,0 +1,229 @@
+package ReportFunctions
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"goScan/ReadFunctions"
+)
+
+// SchemaVersion is the version of the report layout. Bump it whenever a field is
+// renamed or removed so downstream pipelines can tell report generations apart.
+const SchemaVersion = "1.0"
+
+// ToolVersion is the goScan build recorded in every report, set at build time with
+// -ldflags "-X goScan/ReportFunctions.ToolVersion=v1.2.3".
+var ToolVersion = "dev"
+
+// ScanMetadata describes the scan that produced a report.
+type ScanMetadata struct {
+	Tool        string            `json:"tool"`
+	ToolVersion string            `json:"tool_version"`
+	Hostname    string            `json:"hostname,omitempty"`
+	Target      string            `json:"target"` // file or directory that was scanned
+	StartedAt   time.Time         `json:"started_at"`
+	Options     map[string]string `json:"options"`    // command-line options used, by flag name
+	RiskModel   string            `json:"risk_model"` // how each file's risk_score and confidence_score are derived
+}
+
+// NewScanMetadata fills in the tool and host details for a scan of target.
+func NewScanMetadata(target string, options map[string]string) ScanMetadata {
+	hostname, _ := os.Hostname()
+	return ScanMetadata{
+		Tool:        "goScan",
+		ToolVersion: ToolVersion,
+		Hostname:    hostname,
+		Target:      target,
+		StartedAt:   time.Now().UTC(),
+		Options:     options,
+		RiskModel:   ReadFunctions.RiskModel,
+	}
+}
+
+// Writer streams scan results to a report as they are produced, so a report for a
+// very large scan never has to be held in memory.
+type Writer interface {
+	// WriteFile appends one file result to the report.
+	WriteFile(fileAttr ReadFunctions.FileAttributes) error
+	// Close writes the summary, flushes and closes the underlying file.
+	Close(summary ReadFunctions.ScanSummary) error
+}
+
+// writers maps each report format to the function that starts a report in it.
+var writers = map[string]func(io.WriteCloser, ScanMetadata) (Writer, error){
+	"json":   newJSONWriter,
+	"ndjson": newNDJSONWriter,
+}
+
+// CheckFormat returns an error unless format is one Create accepts.
+func CheckFormat(format string) error {
+	if _, ok := writers[format]; !ok {
+		return fmt.Errorf("unknown report format %q, expected json or ndjson", format)
+	}
+	return nil
+}
+
+// Create opens path and starts a report in the given format, "json" for a single
+// versioned document or "ndjson" for one record per line.
+func Create(path, format string, meta ScanMetadata) (Writer, error) {
+	if err := CheckFormat(format); err != nil {
+		return nil, err
+	}
+	newWriter := writers[format]
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := newWriter(file, meta)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// bufferedFile pairs a buffered writer with the file underneath it.
+type bufferedFile struct {
+	*bufio.Writer
+	file io.WriteCloser
+}
+
+func newBufferedFile(file io.WriteCloser) bufferedFile {
+	return bufferedFile{Writer: bufio.NewWriter(file), file: file}
+}
+
+func (b bufferedFile) close() error {
+	if err := b.Flush(); err != nil {
+		_ = b.file.Close()
+		return err
+	}
+	return b.file.Close()
+}
+
+// jsonWriter produces a single JSON document:
+//
+//	{"schema_version": "1.0", "scan": {...}, "files": [...], "summary": {...}, "completed_at": "..."}
+//
+// The files array is written incrementally; the document is only valid once Close returns.
+type jsonWriter struct {
+	out   bufferedFile
+	files int
+}
+
+func newJSONWriter(file io.WriteCloser, meta ScanMetadata) (Writer, error) {
+	w := &jsonWriter{out: newBufferedFile(file)}
+
+	scan, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w.out, "{\n  \"schema_version\": %q,\n  \"scan\": %s,\n  \"files\": [", SchemaVersion, scan); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *jsonWriter) WriteFile(fileAttr ReadFunctions.FileAttributes) error {
+	data, err := json.Marshal(fileAttr)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n    "
+	if w.files == 0 {
+		sep = "\n    "
+	}
+	w.files++
+
+	if _, err := w.out.WriteString(sep); err != nil {
+		return err
+	}
+	_, err = w.out.Write(data)
+	return err
+}
+
+func (w *jsonWriter) Close(summary ReadFunctions.ScanSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		_ = w.out.close()
+		return err
+	}
+	completed, err := json.Marshal(time.Now().UTC())
+	if err != nil {
+		_ = w.out.close()
+		return err
+	}
+
+	closeFiles := "]"
+	if w.files > 0 {
+		closeFiles = "\n  ]"
+	}
+	if _, err := fmt.Fprintf(w.out, "%s,\n  \"summary\": %s,\n  \"completed_at\": %s\n}\n", closeFiles, data, completed); err != nil {
+		_ = w.out.close()
+		return err
+	}
+	return w.out.close()
+}
+
+// NDJSON record types, written as the "record_type" field of every line.
+const (
+	RecordScan    = "scan"
+	RecordFile    = "file"
+	RecordSummary = "summary"
+)
+
+// ndjsonWriter produces newline-delimited JSON: a "scan" record, one "file" record per
+// result as it finishes, and a closing "summary" record. Each line is flushed as it is
+// written so a pipeline tailing the file can ingest results while the scan runs.
+type ndjsonWriter struct {
+	out bufferedFile
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(file io.WriteCloser, meta ScanMetadata) (Writer, error) {
+	w := &ndjsonWriter{out: newBufferedFile(file)}
+	w.enc = json.NewEncoder(w.out)
+
+	err := w.write(struct {
+		RecordType    string `json:"record_type"`
+		SchemaVersion string `json:"schema_version"`
+		ScanMetadata
+	}{RecordScan, SchemaVersion, meta})
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *ndjsonWriter) WriteFile(fileAttr ReadFunctions.FileAttributes) error {
+	return w.write(struct {
+		RecordType string `json:"record_type"`
+		ReadFunctions.FileAttributes
+	}{RecordFile, fileAttr})
+}
+
+func (w *ndjsonWriter) Close(summary ReadFunctions.ScanSummary) error {
+	err := w.write(struct {
+		RecordType string `json:"record_type"`
+		ReadFunctions.ScanSummary
+		CompletedAt time.Time `json:"completed_at"`
+	}{RecordSummary, summary, time.Now().UTC()})
+	if err != nil {
+		_ = w.out.close()
+		return err
+	}
+	return w.out.close()
+}
+
+func (w *ndjsonWriter) write(record any) error {
+	if err := w.enc.Encode(record); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
 
 	"goScan/ReadFunctions"
+	"goScan/RegexProcessing"
+	"goScan/ReportFunctions"
 )
 
 func main() {
@@ -14,7 +21,16 @@ func main() {
 	fsScan := flag.Bool("scan", false, "Enable scanning on the file system, requires -path")
 	fsPath := flag.String("path", "", "Path to scan for files")
 	help := flag.Bool("help", false, "Show help")
-	writeJSON := flag.Bool("writeJSON", false, "Write results to a JSON file (not implemented yet)")
+	writeJSON := flag.Bool("writeJSON", false, "Write results to a JSON report, see -out and -format")
+	outFile := flag.String("out", "", "Report file to write, implies -writeJSON (default goScan-report.json or .ndjson)")
+	format := flag.String("format", "json", "Report format: json for a single document, ndjson for one record per line")
+	workers := flag.Int("workers", runtime.NumCPU(), "Number of files to scan concurrently with -scan")
+	sortResults := flag.Bool("sort", false, "Report -scan results in path order instead of as they finish")
+	locales := flag.String("locales", "us", "Comma-separated countries whose national IDs to detect as well as the US: "+
+		strings.Join(RegexProcessing.Locales(), ", ")+", or all")
+	minConfidence := flag.Float64("min-confidence", 0, "Only report detections with at least this confidence, from 0 to 1")
+	revealCards := flag.Bool("reveal-card-numbers", false, "Report payment card numbers in full instead of only their first six and last four digits")
+	fileTimeout := flag.Duration("timeout", 0, "Maximum time to spend on a single file with -scan, e.g. 30s (0 for no limit)")
 	flag.Parse()
 
 	if *fsFile == "" && !*fsScan && *fsPath == "" || *help {
@@ -22,10 +38,63 @@ func main() {
 		return
 	}
 
+	if *fsScan && *fsPath == "" {
+		usageError("You must specify a path to scan for files")
+	}
+
+	if *fsPath != "" && !*fsScan {
+		usageError("-path is only used with -scan")
+	}
+
+	if *minConfidence < 0 || *minConfidence > 1 {
+		usageError("-min-confidence must be between 0 and 1")
+	}
+
+	if err := ReportFunctions.CheckFormat(*format); err != nil {
+		usageError(err.Error())
+	}
+
+	ReadFunctions.SetScanner(RegexProcessing.ScanSegment)
+	ReadFunctions.SetMinConfidence(*minConfidence)
+	ReadFunctions.SetRevealCardNumbers(*revealCards)
+	if err := RegexProcessing.SetLocales(strings.Split(*locales, ",")); err != nil {
+		usageError(err.Error())
+	}
+
+	var report ReportFunctions.Writer
+	var reportPath string
+	var summary ReadFunctions.ScanSummary
+	if *writeJSON || *outFile != "" {
+		path := *outFile
+		if path == "" {
+			path = "goScan-report." + *format
+		}
+		reportPath = path
+		target := *fsFile
+		if target == "" {
+			target = *fsPath
+		}
+
+		var err error
+		report, err = ReportFunctions.Create(path, *format, ReportFunctions.NewScanMetadata(target, flagValues()))
+		if err != nil {
+			fmt.Printf("Error creating report %s: %v\n", path, err)
+			return
+		}
+		defer func() {
+			if err := report.Close(summary); err != nil {
+				fmt.Printf("Error writing report %s: %v\n", path, err)
+				return
+			}
+			fmt.Printf("Report written to %s\n", path)
+		}()
+	}
+
 	if *fsFile != "" {
 		var fileAttr = ReadFunctions.FileAttributes{}
 		fileAttr, err := ReadFunctions.DetectFileType(*fsFile)
 		if err != nil {
+			fmt.Printf("Error detecting file type for %s: %v\n", *fsFile, err)
 			return
 		}
 
@@ -35,25 +104,61 @@ func main() {
 			return
 		}
 
-		if fileAttr.TotalPHICount > 0 || fileAttr.TotalPIICount > 0 {
+		if fileAttr.TotalPHICount > 0 || fileAttr.TotalPIICount > 0 || fileAttr.TotalSecretCount > 0 {
 			showDetections(fileAttr)
 		}
 
+		summary.Add(fileAttr)
+		if report != nil {
+			if err := report.WriteFile(fileAttr); err != nil {
+				fmt.Printf("Error writing report: %v\n", err)
+			}
+		}
 	}
 
-	if *fsScan && *fsPath != "" {
+	if *fsScan {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
 
-	} else if *fsScan && *fsPath != "" {
-		fmt.Println("You must specify a path to scan for files")
-		flag.Usage()
-		return
+		opts := ReadFunctions.ScanOptions{
+			Workers:     *workers,
+			FileTimeout: *fileTimeout,
+			Sorted:      *sortResults,
+		}
+		if reportPath != "" {
+			opts.Exclude = []string{reportPath} // don't scan the report while writing it
+		}
+		var err error
+		summary, err = ReadFunctions.ScanDirectory(ctx, *fsPath, opts, func(fileAttr ReadFunctions.FileAttributes) error {
+			showFileSummary(fileAttr)
+			if report != nil {
+				return report.WriteFile(fileAttr)
+			}
+			return nil
+		})
+		showScanSummary(summary)
+		if err != nil {
+			fmt.Printf("Error scanning path %s: %v\n", *fsPath, err)
+			return
+		}
 	}
 
-	if *writeJSON {
-		fmt.Println("Writing results to JSON is not implemented yet")
-		// Implement JSON writing logic here
-	}
+}
+
+// usageError reports a problem with the command-line flags and exits.
+func usageError(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	flag.Usage()
+	os.Exit(2)
+}
 
+// flagValues returns the effective value of every command-line flag, for the report.
+func flagValues() map[string]string {
+	values := map[string]string{}
+	flag.VisitAll(func(f *flag.Flag) {
+		values[f.Name] = f.Value.String()
+	})
+	return values
 }
 
 func showDetections(fileAttr ReadFunctions.FileAttributes) {
@@ -61,7 +166,7 @@ func showDetections(fileAttr ReadFunctions.FileAttributes) {
 		fmt.Printf("Total PII Count: %d\n", fileAttr.TotalPIICount)
 		for _, pii := range fileAttr.PIIDetections {
 			fmt.Printf("PII Detected: Type: %s, Value: %s, Redacted: %s, Confidence: %.2f\n",
-				pii.Type, pii.Value, pii.RedactedValue, pii.Confidence)
+				detectionType(pii), pii.Value, pii.RedactedValue, pii.Confidence)
 		}
 	} else {
 		fmt.Println("No PII detected in the file.")
@@ -71,9 +176,57 @@ func showDetections(fileAttr ReadFunctions.FileAttributes) {
 		fmt.Printf("Total PHI Count: %d\n", fileAttr.TotalPHICount)
 		for _, phi := range fileAttr.PHIDetections {
 			fmt.Printf("PHI Detected: Type: %s, Value: %s, Redacted: %s, Confidence: %.2f\n",
-				phi.Type, phi.Value, phi.RedactedValue, phi.Confidence)
+				detectionType(phi), phi.Value, phi.RedactedValue, phi.Confidence)
 		}
 	} else {
 		fmt.Println("No PHI detected in the file.")
 	}
+
+	if fileAttr.TotalSecretCount > 0 {
+		fmt.Printf("Total Secret Count: %d\n", fileAttr.TotalSecretCount)
+		for _, secret := range fileAttr.SecretDetections {
+			fmt.Printf("Secret Detected: Type: %s, Severity: %s, Redacted: %s, Confidence: %.2f\n",
+				detectionType(secret), secret.Severity, secret.RedactedValue, secret.Confidence)
+		}
+	}
+
+	for _, column := range fileAttr.ColumnSummaries {
+		fmt.Printf("Column Summary: %s (%d of %d values)\n",
+			column.Description, column.TypeCounts[column.DominantType], column.Values)
+	}
+
+	if fileAttr.DocumentType != "" {
+		var signals []string
+		for _, s := range fileAttr.DocumentSignals {
+			signals = append(signals, fmt.Sprintf("%s x%d", s.Signal, s.Count))
+		}
+		fmt.Printf("Document Type: %s (confidence %.2f; signals: %s)\n",
+			fileAttr.DocumentType, fileAttr.DocumentTypeConfidence, strings.Join(signals, ", "))
+	}
+	fmt.Printf("Risk Score: %.2f (confidence %.2f)\n", fileAttr.RiskScore, fileAttr.ConfidenceScore)
+	fmt.Printf("Risk Derivation: %s\n", fileAttr.RiskFactors.Derivation)
+}
+
+// detectionType describes a detection's type, with its subtype when it has one.
+func detectionType(d ReadFunctions.PIIDetection) string {
+	if d.Subtype == "" {
+		return d.Type
+	}
+	return d.Type + " (" + d.Subtype + ")"
+}
+
+func showFileSummary(fileAttr ReadFunctions.FileAttributes) {
+	if fileAttr.Status == "error" {
+		fmt.Printf("%s: error: %s\n", fileAttr.FilePath, strings.Join(fileAttr.Errors, "; "))
+		return
+	}
+	fmt.Printf("%s (%s): PII: %d, PHI: %d, Secrets: %d\n",
+		fileAttr.FilePath, fileAttr.FileType, fileAttr.TotalPIICount, fileAttr.TotalPHICount, fileAttr.TotalSecretCount)
+}
+
+func showScanSummary(summary ReadFunctions.ScanSummary) {
+	fmt.Printf("\nFiles scanned: %d, with findings: %d, skipped: %d, errors: %d\n",
+		summary.FilesScanned, summary.FilesWithFindings, summary.FilesSkipped, summary.FilesErrored)
+	fmt.Printf("Total PII Count: %d, Total PHI Count: %d, Total Secret Count: %d\n",
+		summary.TotalPIICount, summary.TotalPHICount, summary.TotalSecretCount)
 }